@@ -1,30 +1,49 @@
 package bcrypto
 
 import (
-	. "github.com/detailyang/go-bprimitives"
+	"errors"
+	"fmt"
+	"strings"
 )
 
 type AddressType int
-type Network int
 
 const (
 	AddressP2PKH AddressType = iota
 	AddressP2SH
+	AddressP2WPKH
+	AddressP2WSH
+	AddressP2TR
 )
 
 const (
-	Mainet Network = iota
-	Testnet
+	// AddressHashSize is the size of the Hash160 carried by P2PKH and P2SH addresses
+	AddressHashSize = 20
+	// WitnessScriptHashSize is the size of the SHA256 carried by P2WSH addresses
+	WitnessScriptHashSize = 32
+	// TaprootOutputKeySize is the size of the x-only output key carried by P2TR addresses
+	TaprootOutputKeySize = 32
+)
+
+var (
+	// ErrAddressBadVersion represents the version byte does not map to a known address
+	ErrAddressBadVersion = errors.New("bad address version")
+	// ErrAddressBadLength represents the payload length does not match the address kind
+	ErrAddressBadLength = errors.New("bad address length")
+	// ErrAddressBadNetwork represents the address belongs to another network
+	ErrAddressBadNetwork = errors.New("bad address network")
+	// ErrAddressBadKind represents the address kind is unknown
+	ErrAddressBadKind = errors.New("bad address kind")
 )
 
 // https://en.bitcoin.it/wiki/Address
 type Address struct {
 	Kind    AddressType
 	Network Network
-	Hash    Hash
+	Hash    []byte
 }
 
-func NewAddress(kind AddressType, network Network, hash Hash) *Address {
+func NewAddress(kind AddressType, network Network, hash []byte) *Address {
 	return &Address{
 		Kind:    kind,
 		Network: network,
@@ -32,8 +51,204 @@ func NewAddress(kind AddressType, network Network, hash Hash) *Address {
 	}
 }
 
-// func (a Address) GeneratePrivateKey() PrivateKey
+// ParseAddress decodes a base58check or bech32 address and infers its kind and network
+func ParseAddress(str string) (*Address, error) {
+	if network, ok := segwitAddressNetwork(str); ok {
+		return parseSegwitAddress(str, network)
+	}
+
+	data, version, err := Base58DecodeCheck(str)
+	if err != nil {
+		return nil, err
+	}
+
+	network, kind, err := NetworkByAddressVersion(version)
+	if err != nil {
+		return nil, ErrAddressBadVersion
+	}
+
+	if len(data) != AddressHashSize {
+		return nil, ErrAddressBadLength
+	}
+
+	return NewAddress(kind, network, data), nil
+}
+
+func segwitAddressNetwork(str string) (Network, bool) {
+	pos := strings.LastIndexByte(str, '1')
+	if pos < 1 {
+		return 0, false
+	}
+
+	network, err := NetworkByHRP(str[:pos])
+	if err != nil {
+		return 0, false
+	}
+
+	return network, true
+}
+
+func parseSegwitAddress(str string, network Network) (*Address, error) {
+	params, err := network.Params()
+	if err != nil {
+		return nil, err
+	}
+
+	version, program, err := SegwitAddressDecode(params.Bech32HRP, str)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case version == 0 && len(program) == AddressHashSize:
+		return NewAddress(AddressP2WPKH, network, program), nil
+	case version == 0 && len(program) == WitnessScriptHashSize:
+		return NewAddress(AddressP2WSH, network, program), nil
+	case version == 1 && len(program) == TaprootOutputKeySize:
+		return NewAddress(AddressP2TR, network, program), nil
+	case version <= 1:
+		return nil, ErrAddressBadLength
+	}
+
+	return nil, ErrAddressBadVersion
+}
+
+// ParseAddressForNetwork decodes the address with the prefixes of the network,
+// networks sharing prefixes (e.g. testnet and signet) are told apart this way
+func ParseAddressForNetwork(str string, network Network) (*Address, error) {
+	params, err := network.Params()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := segwitAddressNetwork(str); ok {
+		if !strings.EqualFold(str[:strings.LastIndexByte(str, '1')], params.Bech32HRP) {
+			return nil, ErrAddressBadNetwork
+		}
+
+		return parseSegwitAddress(str, network)
+	}
+
+	data, version, err := Base58DecodeCheck(str)
+	if err != nil {
+		return nil, err
+	}
+
+	var kind AddressType
+	switch version {
+	case params.PubKeyHashAddrID:
+		kind = AddressP2PKH
+	case params.ScriptHashAddrID:
+		kind = AddressP2SH
+	default:
+		if _, _, err := NetworkByAddressVersion(version); err == nil {
+			return nil, ErrAddressBadNetwork
+		}
+		return nil, ErrAddressBadVersion
+	}
+
+	if len(data) != AddressHashSize {
+		return nil, ErrAddressBadLength
+	}
+
+	return NewAddress(kind, network, data), nil
+}
+
+// IsSegwit reports whether the address is encoded as a witness program
+func (a *Address) IsSegwit() bool {
+	return a.Kind == AddressP2WPKH || a.Kind == AddressP2WSH || a.Kind == AddressP2TR
+}
+
+// Version returns the base58check version byte of the address
+func (a *Address) Version() (byte, error) {
+	if a.IsSegwit() {
+		return 0, ErrAddressBadKind
+	}
+
+	params, err := a.Network.Params()
+	if err != nil {
+		return 0, ErrAddressBadNetwork
+	}
+
+	switch a.Kind {
+	case AddressP2PKH:
+		return params.PubKeyHashAddrID, nil
+	case AddressP2SH:
+		return params.ScriptHashAddrID, nil
+	}
+
+	return 0, ErrAddressBadKind
+}
+
+// Encode returns the string form of the address
+func (a *Address) Encode() (string, error) {
+	switch a.Kind {
+	case AddressP2WPKH:
+		return a.encodeSegwit(0, AddressHashSize)
+	case AddressP2WSH:
+		return a.encodeSegwit(0, WitnessScriptHashSize)
+	case AddressP2TR:
+		return a.encodeSegwit(1, TaprootOutputKeySize)
+	}
+
+	version, err := a.Version()
+	if err != nil {
+		return "", err
+	}
 
-// func (a *Address) String() string {
+	if len(a.Hash) != AddressHashSize {
+		return "", ErrAddressBadLength
+	}
 
-// }
+	return Base58EncodeCheck(a.Hash, version), nil
+}
+
+func (a *Address) encodeSegwit(version byte, size int) (string, error) {
+	params, err := a.Network.Params()
+	if err != nil {
+		return "", ErrAddressBadNetwork
+	}
+
+	if len(a.Hash) != size {
+		return "", ErrAddressBadLength
+	}
+
+	return SegwitAddressEncode(params.Bech32HRP, version, a.Hash)
+}
+
+// ScriptPubKey returns the output script paying to the address
+func (a *Address) ScriptPubKey() ([]byte, error) {
+	if _, err := a.Encode(); err != nil {
+		return nil, err
+	}
+
+	switch a.Kind {
+	case AddressP2PKH:
+		// OP_DUP OP_HASH160 <hash> OP_EQUALVERIFY OP_CHECKSIG
+		script := append([]byte{0x76, 0xa9, AddressHashSize}, a.Hash...)
+		return append(script, 0x88, 0xac), nil
+	case AddressP2SH:
+		// OP_HASH160 <hash> OP_EQUAL
+		script := append([]byte{0xa9, AddressHashSize}, a.Hash...)
+		return append(script, 0x87), nil
+	case AddressP2WPKH, AddressP2WSH:
+		// OP_0 <program>
+		return append([]byte{0x00, byte(len(a.Hash))}, a.Hash...), nil
+	case AddressP2TR:
+		// OP_1 <program>
+		return append([]byte{0x51, byte(len(a.Hash))}, a.Hash...), nil
+	}
+
+	return nil, ErrAddressBadKind
+}
+
+// String returns the encoded address, or a placeholder naming the error if it
+// can't be encoded, use Encode to handle the error
+func (a *Address) String() string {
+	str, err := a.Encode()
+	if err != nil {
+		return fmt.Sprintf("Address(invalid: %v)", err)
+	}
+
+	return str
+}
@@ -0,0 +1,131 @@
+package bcrypto
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+func TestAddress(t *testing.T) {
+	tests := []struct {
+		kind    AddressType
+		network Network
+		hash    string
+		expect  string
+	}{
+		{AddressP2PKH, Mainet, "751e76e8199196d454941c45d1b3a323f1433bd6", "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH"},
+		{AddressP2PKH, Testnet, "751e76e8199196d454941c45d1b3a323f1433bd6", "mrCDrCybB6J1vRfbwM5hemdJz73FwDBC8r"},
+		{AddressP2SH, Mainet, "f815b036d9bbbce5e9f2a00abd1bf3dc91e95510", "3QJmV3qfvL9SuYo34YihAf3sRCW3qSinyC"},
+		{AddressP2SH, Testnet, "c579342c2c4c9220205e2cdc285617040c924a0a", "2NBFNJTktNa7GZusGbDbGKRZTxdK9VVez3n"},
+		{AddressP2WPKH, Mainet, "751e76e8199196d454941c45d1b3a323f1433bd6", "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4"},
+		{AddressP2WSH, Testnet, "1863143c14c5166804bd19203356da136c985678cd4d27a1b8c6329604903262", "tb1qrp33g0q5c5txsp9arysrx4k6zdkfs4nce4xj0gdcccefvpysxf3q0sl5k7"},
+		{AddressP2TR, Mainet, "79be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798", "bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqzk5jj0"},
+		{AddressP2TR, Testnet, "000000c4a5cad46221b2a187905e5266362b99d5e91c6ce24d165dab93e86433", "tb1pqqqqp399et2xygdj5xreqhjjvcmzhxw4aywxecjdzew6hylgvsesf3hn0c"},
+		{AddressP2WPKH, Regtest, "751e76e8199196d454941c45d1b3a323f1433bd6", "bcrt1qw508d6qejxtdg4y5r3zarvary0c5xw7kygt080"},
+	}
+
+	for _, test := range tests {
+		hash, _ := hex.DecodeString(test.hash)
+		address := NewAddress(test.kind, test.network, hash)
+		if address.String() != test.expect {
+			t.Errorf("expect %s got %s", test.expect, address.String())
+		}
+
+		parsed, err := ParseAddress(test.expect)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if parsed.Kind != test.kind || parsed.Network != test.network || hex.EncodeToString(parsed.Hash) != test.hash {
+			t.Errorf("expect %d %d %s got %d %d %x", test.kind, test.network, test.hash, parsed.Kind, parsed.Network, parsed.Hash)
+		}
+	}
+}
+
+func TestBadAddress(t *testing.T) {
+	hash, _ := hex.DecodeString("751e76e8199196d454941c45d1b3a323f1433bd6")
+
+	_, err := ParseAddress(Base58EncodeCheck(hash, 0x30))
+	if err != ErrAddressBadVersion {
+		t.Errorf("expect ErrAddressBadVersion got %v", err)
+	}
+
+	_, err = ParseAddress(Base58EncodeCheck(hash[:19], 0x00))
+	if err != ErrAddressBadLength {
+		t.Errorf("expect ErrAddressBadLength got %v", err)
+	}
+
+	_, err = ParseAddressForNetwork("1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH", Testnet)
+	if err != ErrAddressBadNetwork {
+		t.Errorf("expect ErrAddressBadNetwork got %v", err)
+	}
+
+	_, err = ParseAddress("1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMh")
+	if err != ErrBadChecksum {
+		t.Errorf("expect ErrBadChecksum got %v", err)
+	}
+
+	_, err = NewAddress(AddressP2PKH, Mainet, hash[:10]).Encode()
+	if err != ErrAddressBadLength {
+		t.Errorf("expect ErrAddressBadLength got %v", err)
+	}
+
+	_, err = NewAddress(AddressP2WSH, Mainet, hash).Encode()
+	if err != ErrAddressBadLength {
+		t.Errorf("expect ErrAddressBadLength got %v", err)
+	}
+
+	_, err = ParseAddress("bc1pw508d6qejxtdg4y5r3zarvary0c5xw7kw508d6qejxtdg4y5r3zarvary0c5xw7kt5nd6y")
+	if err != ErrAddressBadLength {
+		t.Errorf("expect ErrAddressBadLength got %v", err)
+	}
+
+	_, err = ParseAddress("bc1zw508d6qejxtdg4y5r3zarvaryvaxxpcs")
+	if err != ErrAddressBadVersion {
+		t.Errorf("expect ErrAddressBadVersion got %v", err)
+	}
+
+	_, err = ParseAddress("bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqh2y7hd")
+	if err != ErrSegwitBadEncoding {
+		t.Errorf("expect ErrSegwitBadEncoding got %v", err)
+	}
+
+	_, err = ParseAddressForNetwork("bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4", Testnet)
+	if err != ErrAddressBadNetwork {
+		t.Errorf("expect ErrAddressBadNetwork got %v", err)
+	}
+	invalid := NewAddress(AddressType(-1), Mainet, hash)
+	if _, err := invalid.Encode(); err != ErrAddressBadKind {
+		t.Errorf("expect ErrAddressBadKind got %v", err)
+	}
+
+	if expect := "Address(invalid: bad address kind)"; invalid.String() != expect {
+		t.Errorf("expect %s got %s", expect, invalid.String())
+	}
+}
+
+func TestParseAddressForNetwork(t *testing.T) {
+	for _, network := range []Network{Testnet, Regtest, Signet} {
+		address, err := ParseAddressForNetwork("mrCDrCybB6J1vRfbwM5hemdJz73FwDBC8r", network)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if address.Network != network || address.Kind != AddressP2PKH {
+			t.Errorf("expect %s p2pkh got %s %d", network, address.Network, address.Kind)
+		}
+	}
+
+	address, err := ParseAddressForNetwork("tb1qrp33g0q5c5txsp9arysrx4k6zdkfs4nce4xj0gdcccefvpysxf3q0sl5k7", Signet)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if address.Network != Signet || address.Kind != AddressP2WSH {
+		t.Errorf("expect signet p2wsh got %s %d", address.Network, address.Kind)
+	}
+
+	_, err = ParseAddressForNetwork("tb1qrp33g0q5c5txsp9arysrx4k6zdkfs4nce4xj0gdcccefvpysxf3q0sl5k7", Regtest)
+	if err != ErrAddressBadNetwork {
+		t.Errorf("expect ErrAddressBadNetwork got %v", err)
+	}
+}
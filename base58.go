@@ -68,31 +68,47 @@ func checksum(data []byte) []byte {
 // Base58EncodeCheck is used for encoding Bitcoin addresses
 // see https://en.bitcoin.it/wiki/Base58Check_encoding
 func Base58EncodeCheck(data []byte, version byte) string {
-	b := make([]byte, 0, 1+len(data)+4)
-	b = append(append(b, version), data...)
-	return Base58Encode(append(b, checksum(b)...))
+	return Base58EncodeCheckVersion(data, []byte{version})
 }
 
 // Base58DecodeCheck is used for decoding Bitcoin addresses
 // see https://en.bitcoin.it/wiki/Base58Check_encoding
 func Base58DecodeCheck(str string) ([]byte, byte, error) {
-	data, err := Base58Decode(str)
+	data, version, err := Base58DecodeCheckVersion(str, 1)
 	if err != nil {
 		return nil, 0, err
 	}
 
+	return data, version[0], nil
+}
+
+// Base58EncodeCheckVersion is Base58EncodeCheck with a multi-byte version,
+// such as the 4-byte versions of extended keys
+func Base58EncodeCheckVersion(data []byte, version []byte) string {
+	b := make([]byte, 0, len(version)+len(data)+4)
+	b = append(append(b, version...), data...)
+	return Base58Encode(append(b, checksum(b)...))
+}
+
+// Base58DecodeCheckVersion is Base58DecodeCheck with a version of versionLen bytes
+func Base58DecodeCheckVersion(str string, versionLen int) ([]byte, []byte, error) {
+	data, err := Base58Decode(str)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	ndata := len(data)
 
 	// version + data + checsum
-	if ndata < 5 {
-		return nil, 0, ErrInvalidFormat
+	if versionLen < 1 || ndata < versionLen+4 {
+		return nil, nil, ErrInvalidFormat
 	}
 
 	if !bytes.Equal(checksum(data[:ndata-4]), data[ndata-4:]) {
-		return nil, 0, ErrBadChecksum
+		return nil, nil, ErrBadChecksum
 	}
 
-	return data[1 : ndata-4], data[0], nil
+	return data[versionLen : ndata-4], data[:versionLen], nil
 }
 
 // Base58Encode represents base58 encode method
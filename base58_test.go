@@ -289,6 +289,34 @@ func TestBase58Check(t *testing.T) {
 
 }
 
+func TestBase58CheckVersion(t *testing.T) {
+	version := []byte{0x04, 0xb2, 0x47, 0x46}
+	data := bytes.Repeat([]byte{0x01}, 74)
+
+	str := Base58EncodeCheckVersion(data, version)
+	if str[:4] != "zpub" {
+		t.Errorf("expect zpub prefix got %s", str)
+	}
+
+	res, resVersion, err := Base58DecodeCheckVersion(str, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(resVersion, version) || !bytes.Equal(res, data) {
+		t.Errorf("expect %x %x got %x %x", version, data, resVersion, res)
+	}
+
+	if Base58EncodeCheckVersion([]byte("abc"), []byte{20}) != Base58EncodeCheck([]byte("abc"), 20) {
+		t.Error("expect a single byte version to match Base58EncodeCheck")
+	}
+
+	// 1 version byte + 4 checksum bytes are too short for a 4-byte version
+	if _, _, err := Base58DecodeCheckVersion("3MNQE1X", 4); err != ErrInvalidFormat {
+		t.Errorf("expect ErrInvalidFormat got %v", err)
+	}
+}
+
 func BenchmarkTrezorBase58EncodeAndDecode(b *testing.B) {
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
@@ -0,0 +1,307 @@
+package bcrypto
+
+import (
+	"errors"
+	"strings"
+)
+
+const (
+	bech32Charset   = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+	bech32MaxLength = 90
+)
+
+// Bech32Encoding represents the checksum variant of a bech32 string
+type Bech32Encoding uint32
+
+const (
+	// EncodingBech32 is the checksum constant defined by BIP173
+	EncodingBech32 Bech32Encoding = 1
+	// EncodingBech32m is the checksum constant defined by BIP350
+	EncodingBech32m Bech32Encoding = 0x2bc830a3
+)
+
+var (
+	// ErrBech32BadLength represents the bech32 string is too short or too long
+	ErrBech32BadLength = errors.New("bech32: bad length")
+	// ErrBech32MixedCase represents the bech32 string mixes upper and lower case
+	ErrBech32MixedCase = errors.New("bech32: mixed case")
+	// ErrBech32BadSeparator represents the separator '1' is missing or misplaced
+	ErrBech32BadSeparator = errors.New("bech32: bad separator")
+	// ErrBech32BadChecksum represents neither the bech32 nor the bech32m checksum matches
+	ErrBech32BadChecksum = errors.New("bech32: bad checksum")
+	// ErrBech32BadVariant represents the checksum is valid for the other variant,
+	// bech32 where bech32m is expected or the reverse
+	ErrBech32BadVariant = errors.New("bech32: bad checksum variant")
+	// ErrBech32BadPadding represents the data can not be regrouped without loss
+	ErrBech32BadPadding = errors.New("bech32: bad padding")
+	// ErrSegwitBadVersion represents the witness version is out of range
+	ErrSegwitBadVersion = errors.New("segwit: bad witness version")
+	// ErrSegwitBadProgram represents the witness program has a bad length
+	ErrSegwitBadProgram = errors.New("segwit: bad witness program")
+	// ErrSegwitBadHRP represents the human-readable part is not the expected one
+	ErrSegwitBadHRP = errors.New("segwit: bad human-readable part")
+	// ErrSegwitBadEncoding represents the checksum variant does not match the witness version
+	ErrSegwitBadEncoding = errors.New("segwit: bad checksum encoding")
+)
+
+var bech32Generator = [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+
+func bech32Polymod(values []byte) uint32 {
+	chk := uint32(1)
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := 0; i < 5; i++ {
+			if (top>>uint(i))&1 == 1 {
+				chk ^= bech32Generator[i]
+			}
+		}
+	}
+
+	return chk
+}
+
+func bech32HRPExpand(hrp string) []byte {
+	n := len(hrp)
+	rv := make([]byte, 0, n*2+1)
+	for i := 0; i < n; i++ {
+		rv = append(rv, hrp[i]>>5)
+	}
+
+	rv = append(rv, 0)
+	for i := 0; i < n; i++ {
+		rv = append(rv, hrp[i]&31)
+	}
+
+	return rv
+}
+
+func bech32Checksum(hrp string, data []byte, encoding Bech32Encoding) []byte {
+	values := append(bech32HRPExpand(hrp), data...)
+	values = append(values, 0, 0, 0, 0, 0, 0)
+	mod := bech32Polymod(values) ^ uint32(encoding)
+
+	rv := make([]byte, 6)
+	for i := 0; i < 6; i++ {
+		rv[i] = byte(mod>>uint(5*(5-i))) & 31
+	}
+
+	return rv
+}
+
+func bech32VerifyChecksum(hrp string, data []byte) (Bech32Encoding, bool) {
+	switch encoding := Bech32Encoding(bech32Polymod(append(bech32HRPExpand(hrp), data...))); encoding {
+	case EncodingBech32, EncodingBech32m:
+		return encoding, true
+	}
+
+	return 0, false
+}
+
+// Bech32Encode encodes the 5-bit groups with the human-readable part
+// see https://github.com/bitcoin/bips/blob/master/bip-0173.mediawiki
+func Bech32Encode(hrp string, data []byte) (string, error) {
+	return bech32Encode(hrp, data, EncodingBech32)
+}
+
+// Bech32mEncode encodes the 5-bit groups with the human-readable part
+// see https://github.com/bitcoin/bips/blob/master/bip-0350.mediawiki
+func Bech32mEncode(hrp string, data []byte) (string, error) {
+	return bech32Encode(hrp, data, EncodingBech32m)
+}
+
+// Bech32Decode decodes the bech32 string into the human-readable part and 5-bit groups
+// see https://github.com/bitcoin/bips/blob/master/bip-0173.mediawiki
+func Bech32Decode(str string) (string, []byte, error) {
+	return bech32DecodeWithEncoding(str, EncodingBech32)
+}
+
+// Bech32mDecode decodes the bech32m string into the human-readable part and 5-bit groups
+// see https://github.com/bitcoin/bips/blob/master/bip-0350.mediawiki
+func Bech32mDecode(str string) (string, []byte, error) {
+	return bech32DecodeWithEncoding(str, EncodingBech32m)
+}
+
+func bech32DecodeWithEncoding(str string, encoding Bech32Encoding) (string, []byte, error) {
+	hrp, data, actual, err := Bech32DecodeAny(str)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if actual != encoding {
+		return "", nil, ErrBech32BadVariant
+	}
+
+	return hrp, data, nil
+}
+
+func bech32Encode(hrp string, data []byte, encoding Bech32Encoding) (string, error) {
+	if len(hrp)+len(data)+7 > bech32MaxLength {
+		return "", ErrBech32BadLength
+	}
+
+	hrp = strings.ToLower(hrp)
+	combined := append(append([]byte{}, data...), bech32Checksum(hrp, data, encoding)...)
+
+	var sb strings.Builder
+	sb.Grow(len(hrp) + 1 + len(combined))
+	sb.WriteString(hrp)
+	sb.WriteByte('1')
+	for _, v := range combined {
+		if v > 31 {
+			return "", ErrInvalidCharacter
+		}
+		sb.WriteByte(bech32Charset[v])
+	}
+
+	return sb.String(), nil
+}
+
+// Bech32DecodeAny decodes either a bech32 or a bech32m string and reports which checksum matched
+func Bech32DecodeAny(str string) (string, []byte, Bech32Encoding, error) {
+	if len(str) < 8 || len(str) > bech32MaxLength {
+		return "", nil, 0, ErrBech32BadLength
+	}
+
+	lower, upper := false, false
+	for i := 0; i < len(str); i++ {
+		c := str[i]
+		if c < 33 || c > 126 {
+			return "", nil, 0, ErrInvalidCharacter
+		}
+		if c >= 'a' && c <= 'z' {
+			lower = true
+		}
+		if c >= 'A' && c <= 'Z' {
+			upper = true
+		}
+	}
+
+	if lower && upper {
+		return "", nil, 0, ErrBech32MixedCase
+	}
+
+	str = strings.ToLower(str)
+	pos := strings.LastIndexByte(str, '1')
+	if pos < 1 || pos+7 > len(str) {
+		return "", nil, 0, ErrBech32BadSeparator
+	}
+
+	hrp := str[:pos]
+	data := make([]byte, 0, len(str)-pos-1)
+	for i := pos + 1; i < len(str); i++ {
+		v := strings.IndexByte(bech32Charset, str[i])
+		if v < 0 {
+			return "", nil, 0, ErrInvalidCharacter
+		}
+		data = append(data, byte(v))
+	}
+
+	encoding, ok := bech32VerifyChecksum(hrp, data)
+	if !ok {
+		return "", nil, 0, ErrBech32BadChecksum
+	}
+
+	return hrp, data[:len(data)-6], encoding, nil
+}
+
+// ConvertBits regroups the data from frombits-bit groups to tobits-bit groups
+func ConvertBits(data []byte, frombits, tobits uint, pad bool) ([]byte, error) {
+	acc := uint32(0)
+	bits := uint(0)
+	maxv := uint32(1)<<tobits - 1
+	rv := make([]byte, 0, len(data)*int(frombits)/int(tobits)+1)
+
+	for _, v := range data {
+		if uint32(v)>>frombits != 0 {
+			return nil, ErrInvalidCharacter
+		}
+
+		acc = acc<<frombits | uint32(v)
+		bits += frombits
+		for bits >= tobits {
+			bits -= tobits
+			rv = append(rv, byte(acc>>bits&maxv))
+		}
+	}
+
+	if pad {
+		if bits > 0 {
+			rv = append(rv, byte(acc<<(tobits-bits)&maxv))
+		}
+	} else if bits >= frombits || acc<<(tobits-bits)&maxv != 0 {
+		return nil, ErrBech32BadPadding
+	}
+
+	return rv, nil
+}
+
+// SegwitAddressEncode encodes the witness program as a segwit address,
+// version 0 uses bech32 and version 1+ uses bech32m
+func SegwitAddressEncode(hrp string, version byte, program []byte) (string, error) {
+	if err := checkWitnessProgram(version, program); err != nil {
+		return "", err
+	}
+
+	data, err := ConvertBits(program, 8, 5, true)
+	if err != nil {
+		return "", err
+	}
+
+	return bech32Encode(hrp, append([]byte{version}, data...), witnessEncoding(version))
+}
+
+// SegwitAddressDecode decodes the segwit address and returns the witness version and program
+func SegwitAddressDecode(hrp, address string) (byte, []byte, error) {
+	dhrp, data, encoding, err := Bech32DecodeAny(address)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if dhrp != strings.ToLower(hrp) {
+		return 0, nil, ErrSegwitBadHRP
+	}
+
+	if len(data) < 1 {
+		return 0, nil, ErrSegwitBadProgram
+	}
+
+	program, err := ConvertBits(data[1:], 5, 8, false)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if err := checkWitnessProgram(data[0], program); err != nil {
+		return 0, nil, err
+	}
+
+	if encoding != witnessEncoding(data[0]) {
+		return 0, nil, ErrSegwitBadEncoding
+	}
+
+	return data[0], program, nil
+}
+
+func witnessEncoding(version byte) Bech32Encoding {
+	if version == 0 {
+		return EncodingBech32
+	}
+
+	return EncodingBech32m
+}
+
+func checkWitnessProgram(version byte, program []byte) error {
+	if version > 16 {
+		return ErrSegwitBadVersion
+	}
+
+	if len(program) < 2 || len(program) > 40 {
+		return ErrSegwitBadProgram
+	}
+
+	if version == 0 && len(program) != 20 && len(program) != 32 {
+		return ErrSegwitBadProgram
+	}
+
+	return nil
+}
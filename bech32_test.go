@@ -0,0 +1,177 @@
+package bcrypto
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func TestBech32(t *testing.T) {
+	valid := []string{
+		"A12UEL5L",
+		"a12uel5l",
+		"an83characterlonghumanreadablepartthatcontainsthenumber1andtheexcludedcharactersbio1tt5tgs",
+		"abcdef1qpzry9x8gf2tvdw0s3jn54khce6mua7lmqqqxw",
+		"11qqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqc8247j",
+		"split1checkupstagehandshakeupstreamerranterredcaperred2y9e3w",
+		"?1ezyfcl",
+	}
+
+	for _, test := range valid {
+		hrp, data, err := Bech32Decode(test)
+		if err != nil {
+			t.Fatalf("expect %s valid got %v", test, err)
+		}
+
+		str, err := Bech32Encode(hrp, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if str != strings.ToLower(test) {
+			t.Errorf("expect %s got %s", strings.ToLower(test), str)
+		}
+
+		// a valid bech32 checksum is a variant error for bech32m, not a corruption
+		_, _, err = Bech32mDecode(test)
+		if err != ErrBech32BadVariant {
+			t.Errorf("%s: expect ErrBech32BadVariant got %v", test, err)
+		}
+	}
+
+	if _, _, err := Bech32mDecode("a1lqfn3q"); err != ErrBech32BadChecksum {
+		t.Errorf("expect ErrBech32BadChecksum got %v", err)
+	}
+
+	invalid := []struct {
+		input  string
+		expect error
+	}{
+		{"\x201nwldj5", ErrInvalidCharacter},
+		{"\x7f1axkwrx", ErrInvalidCharacter},
+		{"an84characterslonghumanreadablepartthatcontainsthenumber1andtheexcludedcharactersbio1569pvx", ErrBech32BadLength},
+		{"pzry9x0s0muk", ErrBech32BadSeparator},
+		{"1pzry9x0s0muk", ErrBech32BadSeparator},
+		{"x1b4n0q5v", ErrInvalidCharacter},
+		{"li1dgmt3", ErrBech32BadSeparator},
+		{"de1lg7wt\xff", ErrInvalidCharacter},
+		{"A1G7SGD8", ErrBech32BadChecksum},
+		{"10a06t8", ErrBech32BadLength},
+		{"1qzzfhee", ErrBech32BadSeparator},
+	}
+
+	for _, test := range invalid {
+		_, _, err := Bech32Decode(test.input)
+		if err != test.expect {
+			t.Errorf("%q: expect %v got %v", test.input, test.expect, err)
+		}
+	}
+}
+
+func TestBech32m(t *testing.T) {
+	valid := []string{
+		"A1LQFN3A",
+		"a1lqfn3a",
+		"an83characterlonghumanreadablepartthatcontainsthetheexcludedcharactersbioandnumber11sg7hg6",
+		"abcdef1l7aum6echk45nj3s0wdvt2fg8x9yrzpqzd3ryx",
+		"11llllllllllllllllllllllllllllllllllllllllllllllllllllllllllllllllllllllllllllllllllludsr8",
+		"split1checkupstagehandshakeupstreamerranterredcaperredlc445v",
+		"?1v759aa",
+	}
+
+	for _, test := range valid {
+		hrp, data, err := Bech32mDecode(test)
+		if err != nil {
+			t.Fatalf("expect %s valid got %v", test, err)
+		}
+
+		str, err := Bech32mEncode(hrp, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if str != strings.ToLower(test) {
+			t.Errorf("expect %s got %s", strings.ToLower(test), str)
+		}
+
+		_, _, err = Bech32Decode(test)
+		if err != ErrBech32BadVariant {
+			t.Errorf("%s: expect ErrBech32BadVariant got %v", test, err)
+		}
+	}
+}
+
+func TestSegwitAddress(t *testing.T) {
+	valid := []struct {
+		hrp     string
+		address string
+		script  string
+	}{
+		{"bc", "BC1QW508D6QEJXTDG4Y5R3ZARVARY0C5XW7KV8F3T4", "0014751e76e8199196d454941c45d1b3a323f1433bd6"},
+		{"tb", "tb1qrp33g0q5c5txsp9arysrx4k6zdkfs4nce4xj0gdcccefvpysxf3q0sl5k7", "00201863143c14c5166804bd19203356da136c985678cd4d27a1b8c6329604903262"},
+		{"tb", "tb1qqqqqp399et2xygdj5xreqhjjvcmzhxw4aywxecjdzew6hylgvsesrxh6hy", "0020000000c4a5cad46221b2a187905e5266362b99d5e91c6ce24d165dab93e86433"},
+		{"bc", "bc1pw508d6qejxtdg4y5r3zarvary0c5xw7kw508d6qejxtdg4y5r3zarvary0c5xw7kt5nd6y", "0128751e76e8199196d454941c45d1b3a323f1433bd6751e76e8199196d454941c45d1b3a323f1433bd6"},
+		{"bc", "BC1SW50QGDZ25J", "1002751e"},
+		{"bc", "bc1zw508d6qejxtdg4y5r3zarvaryvaxxpcs", "0210751e76e8199196d454941c45d1b3a323"},
+		{"tb", "tb1pqqqqp399et2xygdj5xreqhjjvcmzhxw4aywxecjdzew6hylgvsesf3hn0c", "0120000000c4a5cad46221b2a187905e5266362b99d5e91c6ce24d165dab93e86433"},
+		{"bc", "bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqzk5jj0", "012079be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798"},
+	}
+
+	for _, test := range valid {
+		version, program, err := SegwitAddressDecode(test.hrp, test.address)
+		if err != nil {
+			t.Fatalf("expect %s valid got %v", test.address, err)
+		}
+
+		script := append([]byte{version, byte(len(program))}, program...)
+		if hex.EncodeToString(script) != test.script {
+			t.Errorf("expect %s got %x", test.script, script)
+		}
+
+		address, err := SegwitAddressEncode(test.hrp, version, program)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if address != strings.ToLower(test.address) {
+			t.Errorf("expect %s got %s", strings.ToLower(test.address), address)
+		}
+	}
+
+	invalid := []struct {
+		hrp     string
+		address string
+		expect  error
+	}{
+		{"bc", "tc1qw508d6qejxtdg4y5r3zarvary0c5xw7kg3g4ty", ErrSegwitBadHRP},
+		{"bc", "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t5", ErrBech32BadChecksum},
+		{"bc", "BC13W508D6QEJXTDG4Y5R3ZARVARY0C5XW7KN40WF2", ErrSegwitBadVersion},
+		{"bc", "bc1rw5uspcuh", ErrSegwitBadProgram},
+		{"bc", "bc10w508d6qejxtdg4y5r3zarvary0c5xw7kw508d6qejxtdg4y5r3zarvary0c5xw7kw5rljs90", ErrSegwitBadProgram},
+		{"bc", "BC1QR508D6QEJXTDG4Y5R3ZARVARYV98GJ9P", ErrSegwitBadProgram},
+		{"tb", "tb1qrp33g0q5c5txsp9arysrx4k6zdkfs4nce4xj0gdcccefvpysxf3q0sL5k7", ErrBech32MixedCase},
+		{"bc", "bc1zw508d6qejxtdg4y5r3zarvaryvqyzf3du", ErrBech32BadPadding},
+		{"tb", "tb1qrp33g0q5c5txsp9arysrx4k6zdkfs4nce4xj0gdcccefvpysxf3pjxtptv", ErrBech32BadPadding},
+		{"bc", "bc1gmk9yu", ErrSegwitBadProgram},
+		{"bc", "tc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vq5zuyut", ErrSegwitBadHRP},
+		{"bc", "bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqh2y7hd", ErrSegwitBadEncoding},
+		{"tb", "tb1z0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqglt7rf", ErrSegwitBadEncoding},
+		{"bc", "BC1S0XLXVLHEMJA6C4DQV22UAPCTQUPFHLXM9H8Z3K2E72Q4K9HCZ7VQ54WELL", ErrSegwitBadEncoding},
+		{"bc", "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kemeawh", ErrSegwitBadEncoding},
+		{"tb", "tb1q0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vq24jc47", ErrSegwitBadEncoding},
+		{"bc", "bc1p38j9r5y49hruaue7wxjce0updqjuyyx0kh56v8s25huc6995vvpql3jow4", ErrInvalidCharacter},
+		{"bc", "BC130XLXVLHEMJA6C4DQV22UAPCTQUPFHLXM9H8Z3K2E72Q4K9HCZ7VQ7ZWS8R", ErrSegwitBadVersion},
+		{"bc", "bc1pw5dgrnzv", ErrSegwitBadProgram},
+		{"bc", "bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7v8n0nx0muaewav253zgeav", ErrSegwitBadProgram},
+		{"tb", "tb1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vq47Zagq", ErrBech32MixedCase},
+		{"bc", "bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7v07qwwzcrf", ErrBech32BadPadding},
+		{"tb", "tb1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vpggkg4j", ErrBech32BadPadding},
+	}
+
+	for _, test := range invalid {
+		_, _, err := SegwitAddressDecode(test.hrp, test.address)
+		if err != test.expect {
+			t.Errorf("%s: expect %v got %v", test.address, test.expect, err)
+		}
+	}
+}
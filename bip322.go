@@ -0,0 +1,595 @@
+package bcrypto
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"io"
+
+	. "github.com/detailyang/go-bprimitives"
+)
+
+// BIP322Format represents the encoding of a BIP322 signature
+// see https://github.com/bitcoin/bips/blob/master/bip-0322.mediawiki
+type BIP322Format int
+
+const (
+	// BIP322Simple encodes the witness stack of the to_sign input
+	BIP322Simple BIP322Format = iota
+	// BIP322Full encodes the whole to_sign transaction
+	BIP322Full
+)
+
+const (
+	bip322Tag   = "BIP0322-signed-message"
+	opReturn    = 0x6a
+	witnessSize = 2
+)
+
+var (
+	// ErrBIP322UnsupportedAddress represents the address kind can not be signed or verified yet
+	ErrBIP322UnsupportedAddress = errors.New("bip322: unsupported address")
+	// ErrBIP322KeyMismatch represents the private key does not control the address
+	ErrBIP322KeyMismatch = errors.New("bip322: key does not match address")
+	// ErrBIP322BadFormat represents the signature can not be decoded
+	ErrBIP322BadFormat = errors.New("bip322: bad signature format")
+	// ErrBIP322BadTransaction represents the to_sign transaction breaks the BIP322 rules
+	ErrBIP322BadTransaction = errors.New("bip322: bad to_sign transaction")
+	// ErrBIP322Inconclusive represents the to_sign transaction has proof of funds inputs,
+	// which can not be verified without their previous outputs
+	ErrBIP322Inconclusive = errors.New("bip322: inconclusive")
+)
+
+type txInput struct {
+	prevHash  []byte
+	prevIndex uint32
+	script    []byte
+	sequence  uint32
+	witness   [][]byte
+}
+
+type txOutput struct {
+	value  uint64
+	script []byte
+}
+
+type transaction struct {
+	version  uint32
+	inputs   []txInput
+	outputs  []txOutput
+	locktime uint32
+}
+
+// BIP322MessageHash returns the tagged hash committed to by the to_spend transaction
+func BIP322MessageHash(text string) []byte {
+	return TaggedHash(bip322Tag, []byte(text))
+}
+
+// SignMessageBIP322 signs the message for the address with the generic signed message format,
+// P2WPKH and P2TR can be encoded with either format and P2SH-P2WPKH requires BIP322Full.
+// P2TR addresses are signed with the BIP86 key path.
+func SignMessageBIP322(pk *PrivateKey, address *Address, text string, format BIP322Format) (string, error) {
+	if !bip322Supported(address, format) {
+		return "", ErrBIP322UnsupportedAddress
+	}
+
+	derived, err := pk.Address(address.Kind)
+	if err != nil {
+		return "", err
+	}
+
+	if !bytes.Equal(derived.Hash, address.Hash) {
+		return "", ErrBIP322KeyMismatch
+	}
+
+	toSpend, err := bip322ToSpend(address, text)
+	if err != nil {
+		return "", err
+	}
+
+	toSign := bip322ToSign(toSpend.txid())
+	if address.Kind == AddressP2TR {
+		err = bip322SignTaproot(pk, toSpend, toSign)
+	} else {
+		err = bip322SignWitnessV0(pk, address, toSign)
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if format == BIP322Simple {
+		putWitness(&buf, toSign.inputs[0].witness)
+	} else {
+		toSign.serialize(&buf, true)
+	}
+
+	return base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+func bip322SignWitnessV0(pk *PrivateKey, address *Address, toSign *transaction) error {
+	pubkey, err := pk.PublicKey()
+	if err != nil {
+		return err
+	}
+
+	if address.Kind == AddressP2SH {
+		// the redeem script OP_0 <20-byte key hash> is pushed by the script sig
+		redeem := append([]byte{0x00, AddressHashSize}, pubkey.ID()...)
+		toSign.inputs[0].script = append([]byte{byte(len(redeem))}, redeem...)
+	}
+
+	sig, err := pk.SignHash(toSign.witnessV0SigHash(0, pubkey.ID(), 0))
+	if err != nil {
+		return err
+	}
+
+	toSign.inputs[0].witness = [][]byte{append(sig, byte(SigHashAll)), pubkey}
+	return nil
+}
+
+func bip322SignTaproot(pk *PrivateKey, toSpend, toSign *transaction) error {
+	tweaked, err := TaprootTweakPrivateKey(pk, nil)
+	if err != nil {
+		return err
+	}
+
+	auxRand := make([]byte, 32)
+	if _, err := rand.Read(auxRand); err != nil {
+		return err
+	}
+
+	sig, err := tweaked.SignSchnorr(toSign.taprootSigHash(0, toSpend.outputs, SigHashDefault), auxRand)
+	if err != nil {
+		return err
+	}
+
+	toSign.inputs[0].witness = [][]byte{sig}
+	return nil
+}
+
+func bip322Supported(address *Address, format BIP322Format) bool {
+	switch address.Kind {
+	case AddressP2WPKH, AddressP2TR:
+		return true
+	case AddressP2SH:
+		return format == BIP322Full
+	}
+
+	return false
+}
+
+// VerifyMessageBIP322 verifies the generic signed message of the address,
+// a full signature with proof of funds inputs is ErrBIP322Inconclusive
+func VerifyMessageBIP322(address *Address, signature, text string, format BIP322Format) (bool, error) {
+	if !bip322Supported(address, format) {
+		return false, ErrBIP322UnsupportedAddress
+	}
+
+	data, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return false, err
+	}
+
+	toSpend, err := bip322ToSpend(address, text)
+	if err != nil {
+		return false, err
+	}
+
+	toSpendID := toSpend.txid()
+	r := bytes.NewReader(data)
+
+	var toSign *transaction
+	if format == BIP322Simple {
+		witness, err := readWitness(r)
+		if err != nil {
+			return false, ErrBIP322BadFormat
+		}
+
+		toSign = bip322ToSign(toSpendID)
+		toSign.inputs[0].witness = witness
+	} else {
+		toSign, err = parseTransaction(r)
+		if err != nil {
+			return false, ErrBIP322BadFormat
+		}
+
+		if !bip322CheckToSign(toSign, toSpendID) {
+			return false, ErrBIP322BadTransaction
+		}
+	}
+
+	if r.Len() != 0 {
+		return false, ErrBIP322BadFormat
+	}
+
+	// the taproot digest commits to the previous outputs of every input
+	if address.Kind == AddressP2TR {
+		if len(toSign.inputs) > 1 {
+			return false, ErrBIP322Inconclusive
+		}
+
+		return bip322VerifyTaproot(address, toSpend, toSign), nil
+	}
+
+	if !bip322VerifyWitnessV0(address, toSign) {
+		return false, nil
+	}
+
+	if len(toSign.inputs) > 1 {
+		return false, ErrBIP322Inconclusive
+	}
+
+	return true, nil
+}
+
+func bip322VerifyWitnessV0(address *Address, toSign *transaction) bool {
+	input := toSign.inputs[0]
+	if len(input.witness) != witnessSize {
+		return false
+	}
+
+	sig, err := ParseScriptSignature(input.witness[0])
+	if err != nil || sig.HashType != SigHashAll {
+		return false
+	}
+
+	pubkey := NewPublicKey(input.witness[1])
+	if !pubkey.IsCompressed() {
+		return false
+	}
+
+	derived, err := pubkey.Address(address.Network, address.Kind)
+	if err != nil || !bytes.Equal(derived.Hash, address.Hash) {
+		return false
+	}
+
+	if address.Kind == AddressP2SH {
+		redeem := append([]byte{0x00, AddressHashSize}, pubkey.ID()...)
+		if !bytes.Equal(input.script, append([]byte{byte(len(redeem))}, redeem...)) {
+			return false
+		}
+	} else if len(input.script) != 0 {
+		return false
+	}
+
+	return pubkey.Verify(toSign.witnessV0SigHash(0, pubkey.ID(), 0), sig.Signature.Serialize())
+}
+
+// bip322VerifyTaproot checks the key path spend of the BIP86 output key
+func bip322VerifyTaproot(address *Address, toSpend, toSign *transaction) bool {
+	input := toSign.inputs[0]
+	if len(input.witness) != 1 || len(input.script) != 0 {
+		return false
+	}
+
+	// a 65-byte signature carries an explicit SIGHASH_ALL
+	sig, hashType, err := ParseTaprootSignature(input.witness[0])
+	if err != nil || (hashType != SigHashDefault && hashType != SigHashAll) {
+		return false
+	}
+
+	output, err := ParseXOnlyPublicKey(address.Hash)
+	if err != nil {
+		return false
+	}
+
+	return output.Verify(toSign.taprootSigHash(0, toSpend.outputs, hashType), sig)
+}
+
+// bip322ToSpend builds the virtual transaction whose only output carries the message challenge
+func bip322ToSpend(address *Address, text string) (*transaction, error) {
+	challenge, err := address.ScriptPubKey()
+	if err != nil {
+		return nil, err
+	}
+
+	// OP_0 PUSH32 <message hash>
+	script := append([]byte{0x00, 0x20}, BIP322MessageHash(text)...)
+
+	return &transaction{
+		inputs: []txInput{{
+			prevHash:  make([]byte, 32),
+			prevIndex: 0xffffffff,
+			script:    script,
+		}},
+		outputs: []txOutput{{script: challenge}},
+	}, nil
+}
+
+// bip322ToSign builds the virtual transaction spending to_spend, the witness is left empty
+func bip322ToSign(toSpendID []byte) *transaction {
+	return &transaction{
+		inputs:  []txInput{{prevHash: toSpendID}},
+		outputs: []txOutput{{script: []byte{opReturn}}},
+	}
+}
+
+// bip322CheckToSign checks what BIP322 fixes of a full to_sign transaction: the first
+// input spends to_spend:0 and the only output is OP_RETURN of value 0. The version,
+// locktime, sequences and proof of funds inputs are up to the signer.
+func bip322CheckToSign(tx *transaction, toSpendID []byte) bool {
+	if len(tx.inputs) == 0 || len(tx.outputs) != 1 {
+		return false
+	}
+
+	in, out := tx.inputs[0], tx.outputs[0]
+	return bytes.Equal(in.prevHash, toSpendID) &&
+		in.prevIndex == 0 &&
+		out.value == 0 &&
+		bytes.Equal(out.script, []byte{opReturn})
+}
+
+func (tx *transaction) txid() []byte {
+	var buf bytes.Buffer
+	tx.serialize(&buf, false)
+	return DHash256(buf.Bytes()).TakeBytes(0, 32)
+}
+
+func (tx *transaction) hasWitness() bool {
+	for _, in := range tx.inputs {
+		if len(in.witness) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (tx *transaction) serialize(buf *bytes.Buffer, witness bool) {
+	witness = witness && tx.hasWitness()
+
+	putUint32(buf, tx.version)
+	if witness {
+		// marker and flag
+		buf.Write([]byte{0x00, 0x01})
+	}
+
+	putVarInt(buf, uint64(len(tx.inputs)))
+	for _, in := range tx.inputs {
+		buf.Write(in.prevHash)
+		putUint32(buf, in.prevIndex)
+		putVarBytes(buf, in.script)
+		putUint32(buf, in.sequence)
+	}
+
+	putVarInt(buf, uint64(len(tx.outputs)))
+	for _, out := range tx.outputs {
+		putUint64(buf, out.value)
+		putVarBytes(buf, out.script)
+	}
+
+	if witness {
+		for _, in := range tx.inputs {
+			putWitness(buf, in.witness)
+		}
+	}
+
+	putUint32(buf, tx.locktime)
+}
+
+// witnessV0SigHash returns the SIGHASH_ALL digest of the P2WPKH input
+// see https://github.com/bitcoin/bips/blob/master/bip-0143.mediawiki
+func (tx *transaction) witnessV0SigHash(index int, keyHash []byte, amount uint64) []byte {
+	var prevouts, sequences, outputs bytes.Buffer
+	for _, in := range tx.inputs {
+		prevouts.Write(in.prevHash)
+		putUint32(&prevouts, in.prevIndex)
+		putUint32(&sequences, in.sequence)
+	}
+
+	for _, out := range tx.outputs {
+		putUint64(&outputs, out.value)
+		putVarBytes(&outputs, out.script)
+	}
+
+	in := tx.inputs[index]
+
+	var buf bytes.Buffer
+	putUint32(&buf, tx.version)
+	buf.Write(DHash256(prevouts.Bytes()).TakeBytes(0, 32))
+	buf.Write(DHash256(sequences.Bytes()).TakeBytes(0, 32))
+	buf.Write(in.prevHash)
+	putUint32(&buf, in.prevIndex)
+
+	// script code: OP_DUP OP_HASH160 <key hash> OP_EQUALVERIFY OP_CHECKSIG
+	code := append([]byte{0x76, 0xa9, AddressHashSize}, keyHash...)
+	putVarBytes(&buf, append(code, 0x88, 0xac))
+
+	putUint64(&buf, amount)
+	putUint32(&buf, in.sequence)
+	buf.Write(DHash256(outputs.Bytes()).TakeBytes(0, 32))
+	putUint32(&buf, tx.locktime)
+	putUint32(&buf, uint32(SigHashAll))
+
+	return DHash256(buf.Bytes()).TakeBytes(0, 32)
+}
+
+// taprootSigHash returns the key path digest of the input spending prevouts,
+// only SIGHASH_DEFAULT and SIGHASH_ALL are supported
+// see https://github.com/bitcoin/bips/blob/master/bip-0341.mediawiki#common-signature-message
+func (tx *transaction) taprootSigHash(index int, prevouts []txOutput, hashType SigHashType) []byte {
+	var prevHashes, amounts, scripts, sequences, outputs bytes.Buffer
+	for i, in := range tx.inputs {
+		prevHashes.Write(in.prevHash)
+		putUint32(&prevHashes, in.prevIndex)
+		putUint64(&amounts, prevouts[i].value)
+		putVarBytes(&scripts, prevouts[i].script)
+		putUint32(&sequences, in.sequence)
+	}
+
+	for _, out := range tx.outputs {
+		putUint64(&outputs, out.value)
+		putVarBytes(&outputs, out.script)
+	}
+
+	sum := func(buf *bytes.Buffer) []byte {
+		h := sha256.Sum256(buf.Bytes())
+		return h[:]
+	}
+
+	var buf bytes.Buffer
+	// sighash epoch
+	buf.WriteByte(0x00)
+	buf.WriteByte(byte(hashType))
+	putUint32(&buf, tx.version)
+	putUint32(&buf, tx.locktime)
+	buf.Write(sum(&prevHashes))
+	buf.Write(sum(&amounts))
+	buf.Write(sum(&scripts))
+	buf.Write(sum(&sequences))
+	buf.Write(sum(&outputs))
+	// spend type: key path without annex
+	buf.WriteByte(0x00)
+	putUint32(&buf, uint32(index))
+
+	return TaggedHash("TapSighash", buf.Bytes())
+}
+
+func parseTransaction(r *bytes.Reader) (*transaction, error) {
+	tx := &transaction{}
+
+	var err error
+	if tx.version, err = readUint32(r); err != nil {
+		return nil, err
+	}
+
+	witness := false
+	nin, err := readVarInt(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if nin == 0 {
+		// marker is followed by the flag
+		flag, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		if flag != 0x01 {
+			return nil, ErrBIP322BadFormat
+		}
+
+		witness = true
+		if nin, err = readVarInt(r); err != nil {
+			return nil, err
+		}
+	}
+
+	if nin > uint64(r.Len()) {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	tx.inputs = make([]txInput, nin)
+	for i := range tx.inputs {
+		in := &tx.inputs[i]
+		in.prevHash = make([]byte, 32)
+		if _, err := io.ReadFull(r, in.prevHash); err != nil {
+			return nil, err
+		}
+		if in.prevIndex, err = readUint32(r); err != nil {
+			return nil, err
+		}
+		if in.script, err = readVarBytes(r); err != nil {
+			return nil, err
+		}
+		if in.sequence, err = readUint32(r); err != nil {
+			return nil, err
+		}
+	}
+
+	nout, err := readVarInt(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if nout > uint64(r.Len()) {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	tx.outputs = make([]txOutput, nout)
+	for i := range tx.outputs {
+		out := &tx.outputs[i]
+		if out.value, err = readUint64(r); err != nil {
+			return nil, err
+		}
+		if out.script, err = readVarBytes(r); err != nil {
+			return nil, err
+		}
+	}
+
+	if witness {
+		for i := range tx.inputs {
+			if tx.inputs[i].witness, err = readWitness(r); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if tx.locktime, err = readUint32(r); err != nil {
+		return nil, err
+	}
+
+	return tx, nil
+}
+
+func putWitness(buf *bytes.Buffer, witness [][]byte) {
+	putVarInt(buf, uint64(len(witness)))
+	for _, item := range witness {
+		putVarBytes(buf, item)
+	}
+}
+
+func readWitness(r *bytes.Reader) ([][]byte, error) {
+	n, err := readVarInt(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if n > uint64(r.Len()) {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	witness := make([][]byte, n)
+	for i := range witness {
+		if witness[i], err = readVarBytes(r); err != nil {
+			return nil, err
+		}
+	}
+
+	return witness, nil
+}
+
+func putUint32(buf *bytes.Buffer, n uint32) {
+	var b [4]byte
+	binary.LittleEndian.PutUint32(b[:], n)
+	buf.Write(b[:])
+}
+
+func putUint64(buf *bytes.Buffer, n uint64) {
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], n)
+	buf.Write(b[:])
+}
+
+func readUint32(r *bytes.Reader) (uint32, error) {
+	var b [4]byte
+	if _, err := io.ReadFull(r, b[:]); err != nil {
+		return 0, err
+	}
+
+	return binary.LittleEndian.Uint32(b[:]), nil
+}
+
+func readUint64(r *bytes.Reader) (uint64, error) {
+	var b [8]byte
+	if _, err := io.ReadFull(r, b[:]); err != nil {
+		return 0, err
+	}
+
+	return binary.LittleEndian.Uint64(b[:]), nil
+}
@@ -0,0 +1,293 @@
+package bcrypto
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"testing"
+)
+
+func TestBIP322MessageHash(t *testing.T) {
+	tests := []struct {
+		text    string
+		hash    string
+		toSpend string
+		toSign  string
+	}{
+		{
+			"",
+			"c90c269c4f8fcbe6880f72a721ddfbf1914268a794cbb21cfafee13770ae19f1",
+			"c5680aa69bb8d860bf82d4e9cd3504b55dde018de765a91bb566283c545a99a7",
+			"1e9654e951a5ba44c8604c4de6c67fd78a27e81dcadcfe1edf638ba3aaebaed6",
+		},
+		{
+			"Hello World",
+			"f0eb03b1a75ac6d9847f55c624a99169b5dccba2a31f5b23bea77ba270de0a7a",
+			"b79d196740ad5217771c1098fc4a4b51e0535c32236c71f1ea4d61a2d603352b",
+			"88737ae86f2077145f93cc4b153ae9a1cb8d56afa511988c149c5c8c9d93bddf",
+		},
+	}
+
+	address, err := ParseAddress("bc1q9vza2e8x573nczrlzms0wvx3gsqjx7vavgkx0l")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range tests {
+		if hash := hex.EncodeToString(BIP322MessageHash(test.text)); hash != test.hash {
+			t.Errorf("expect %s got %s", test.hash, hash)
+		}
+
+		toSpend, err := bip322ToSpend(address, test.text)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// txids are displayed in reversed byte order
+		id := reverseBytes(toSpend.txid())
+		if hex.EncodeToString(id) != test.toSpend {
+			t.Errorf("expect to_spend %s got %x", test.toSpend, id)
+		}
+
+		id = reverseBytes(bip322ToSign(toSpend.txid()).txid())
+		if hex.EncodeToString(id) != test.toSign {
+			t.Errorf("expect to_sign %s got %x", test.toSign, id)
+		}
+	}
+}
+
+func TestBIP322Simple(t *testing.T) {
+	tests := []struct {
+		text      string
+		signature string
+	}{
+		{"", "AkcwRAIgM2gBAQqvZX15ZiysmKmQpDrG83avLIT492QBzLnQIxYCIBaTpOaD20qRlEylyxFSeEA2ba9YOixpX8z46TSDtS40ASECx/EgAxlkQpQ9hYjgGu6EBCPMVPwVIVJqO4XCsMvViHI="},
+		{"Hello World", "AkcwRAIgZRfIY3p7/DoVTty6YZbWS71bc5Vct9p9Fia83eRmw2QCICK/ENGfwLtptFluMGs2KsqoNSk89pO7F29zJLUx9a/sASECx/EgAxlkQpQ9hYjgGu6EBCPMVPwVIVJqO4XCsMvViHI="},
+	}
+
+	pk, err := NewPrivateKeyFromWIF("L3VFeEujGtevx9w18HD1fhRbCH67Az2dpCymeRE1SoPK6XQtaN2k")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	address, err := ParseAddress("bc1q9vza2e8x573nczrlzms0wvx3gsqjx7vavgkx0l")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range tests {
+		ok, err := VerifyMessageBIP322(address, test.signature, test.text, BIP322Simple)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !ok {
+			t.Errorf("expect %q verified", test.text)
+		}
+
+		// the vectors are signed with low R grinding so only the round trip is checked
+		for _, format := range []BIP322Format{BIP322Simple, BIP322Full} {
+			signature, err := SignMessageBIP322(pk, address, test.text, format)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ok, err := VerifyMessageBIP322(address, signature, test.text, format)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !ok {
+				t.Errorf("expect %q verified with format %d", test.text, format)
+			}
+		}
+	}
+
+	ok, err := VerifyMessageBIP322(address, tests[0].signature, "Hello World", BIP322Simple)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ok {
+		t.Error("expect signature of another message rejected")
+	}
+}
+
+func TestBIP322NestedSegwit(t *testing.T) {
+	pk, err := NewPrivateKeyFromWIF("L3VFeEujGtevx9w18HD1fhRbCH67Az2dpCymeRE1SoPK6XQtaN2k")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	address, err := pk.Address(AddressP2SH)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := SignMessageBIP322(pk, address, "Hello World", BIP322Simple); err != ErrBIP322UnsupportedAddress {
+		t.Errorf("expect ErrBIP322UnsupportedAddress got %v", err)
+	}
+
+	signature, err := SignMessageBIP322(pk, address, "Hello World", BIP322Full)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ok, err := VerifyMessageBIP322(address, signature, "Hello World", BIP322Full)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !ok {
+		t.Error("expect nested segwit signature verified")
+	}
+
+	other, err := ParseAddress("bc1q9vza2e8x573nczrlzms0wvx3gsqjx7vavgkx0l")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ok, _ := VerifyMessageBIP322(other, signature, "Hello World", BIP322Full); ok {
+		t.Error("expect signature rejected for another address")
+	}
+
+	legacy, err := pk.Address(AddressP2PKH)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := SignMessageBIP322(pk, legacy, "Hello World", BIP322Full); err != ErrBIP322UnsupportedAddress {
+		t.Errorf("expect ErrBIP322UnsupportedAddress got %v", err)
+	}
+}
+
+func reverseBytes(b []byte) []byte {
+	rv := make([]byte, len(b))
+	for i := range b {
+		rv[len(b)-1-i] = b[i]
+	}
+
+	return rv
+}
+
+func TestBIP322Taproot(t *testing.T) {
+	pk, err := NewPrivateKeyFromWIF("L3VFeEujGtevx9w18HD1fhRbCH67Az2dpCymeRE1SoPK6XQtaN2k")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	address, err := ParseAddress("bc1ppv609nr0vr25u07u95waq5lucwfm6tde4nydujnu8npg4q75mr5sxq8lt3")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ok, err := VerifyMessageBIP322(address, "AUHd69PrJQEv+oKTfZ8l+WROBHuy9HKrbFCJu7U1iK2iiEy1vMU5EfMtjc+VSHM7aU0SDbak5IUZRVno2P5mjSafAQ==", "Hello World", BIP322Simple)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !ok {
+		t.Error("expect taproot signature verified")
+	}
+
+	for _, format := range []BIP322Format{BIP322Simple, BIP322Full} {
+		signature, err := SignMessageBIP322(pk, address, "Hello World", format)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ok, err := VerifyMessageBIP322(address, signature, "Hello World", format)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !ok {
+			t.Errorf("expect taproot signature verified with format %d", format)
+		}
+
+		ok, err = VerifyMessageBIP322(address, signature, "", format)
+		if err != nil && err != ErrBIP322BadTransaction {
+			t.Fatal(err)
+		}
+
+		if ok {
+			t.Error("expect signature of another message rejected")
+		}
+	}
+}
+
+// BIP322Full leaves the version, locktime and sequence of to_sign to the signer
+func TestBIP322FullToSign(t *testing.T) {
+	pk, err := NewPrivateKeyFromWIF("L3VFeEujGtevx9w18HD1fhRbCH67Az2dpCymeRE1SoPK6XQtaN2k")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, kind := range []AddressType{AddressP2WPKH, AddressP2SH, AddressP2TR} {
+		address, err := pk.Address(kind)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		toSpend, err := bip322ToSpend(address, "Hello World")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		encode := func(toSign *transaction) string {
+			var buf bytes.Buffer
+			toSign.serialize(&buf, true)
+			return base64.StdEncoding.EncodeToString(buf.Bytes())
+		}
+
+		sign := func(toSign *transaction) string {
+			if kind == AddressP2TR {
+				err = bip322SignTaproot(pk, toSpend, toSign)
+			} else {
+				err = bip322SignWitnessV0(pk, address, toSign)
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			return encode(toSign)
+		}
+
+		toSign := bip322ToSign(toSpend.txid())
+		toSign.version = 2
+		toSign.locktime = 800000
+		toSign.inputs[0].sequence = 0xfffffffd
+
+		ok, err := VerifyMessageBIP322(address, sign(toSign), "Hello World", BIP322Full)
+		if err != nil || !ok {
+			t.Errorf("%d: expect nonzero version, locktime and sequence verified got %v", kind, err)
+		}
+
+		// a proof of funds input can not be verified without its previous output,
+		// the taproot signer would need it too so it is added after signing
+		toSign = bip322ToSign(toSpend.txid())
+		funds := txInput{prevHash: make([]byte, 32), prevIndex: 1}
+
+		var signature string
+		if kind == AddressP2TR {
+			sign(toSign)
+			toSign.inputs = append(toSign.inputs, funds)
+			signature = encode(toSign)
+		} else {
+			toSign.inputs = append(toSign.inputs, funds)
+			signature = sign(toSign)
+		}
+
+		if _, err := VerifyMessageBIP322(address, signature, "Hello World", BIP322Full); err != ErrBIP322Inconclusive {
+			t.Errorf("%d: expect ErrBIP322Inconclusive got %v", kind, err)
+		}
+
+		toSign = bip322ToSign(toSpend.txid())
+		toSign.outputs[0].value = 1
+
+		if _, err := VerifyMessageBIP322(address, sign(toSign), "Hello World", BIP322Full); err != ErrBIP322BadTransaction {
+			t.Errorf("%d: expect ErrBIP322BadTransaction got %v", kind, err)
+		}
+	}
+}
@@ -0,0 +1,465 @@
+package bcrypto
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+
+	"github.com/detailyang/go-bcrypto/secp256k1"
+	. "github.com/detailyang/go-bprimitives"
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	// BIP38MaxLot is the largest lot number of an intermediate code
+	BIP38MaxLot = 1048575
+	// BIP38MaxSequence is the largest sequence number of an intermediate code
+	BIP38MaxSequence = 4095
+
+	// bip38KeySize is the length of an encrypted key without version and checksum
+	bip38KeySize = 37
+	// bip38IntermediateSize is the length of an intermediate code without magic and checksum
+	bip38IntermediateSize = 41
+	// bip38ConfirmationSize is the length of a confirmation code without magic and checksum
+	bip38ConfirmationSize = 46
+
+	bip38FlagNonEC      = 0xc0
+	bip38FlagCompressed = 0x20
+	bip38FlagLotSeq     = 0x04
+)
+
+var (
+	// bip38Version and bip38ECVersion prefix the "6P" keys of both modes
+	bip38Version   = []byte{0x01, 0x42}
+	bip38ECVersion = []byte{0x01, 0x43}
+	// bip38Magic and bip38MagicLotSeq prefix the "passphrase" intermediate codes
+	bip38Magic       = []byte{0x2c, 0xe9, 0xb3, 0xe1, 0xff, 0x39, 0xe2, 0x53}
+	bip38MagicLotSeq = []byte{0x2c, 0xe9, 0xb3, 0xe1, 0xff, 0x39, 0xe2, 0x51}
+	// bip38Confirmation prefixes the "cfrm38" confirmation codes
+	bip38Confirmation = []byte{0x64, 0x3b, 0xf6, 0xa8, 0x9a}
+)
+
+var (
+	// ErrBIP38BadFormat represents the encrypted key, intermediate or confirmation code can not be decoded
+	ErrBIP38BadFormat = errors.New("bip38: bad format")
+	// ErrBIP38BadPassphrase represents the decrypted key does not match the address hash
+	ErrBIP38BadPassphrase = errors.New("bip38: bad passphrase")
+	// ErrBIP38BadLotSequence represents the lot or sequence number is out of range
+	ErrBIP38BadLotSequence = errors.New("bip38: bad lot or sequence number")
+)
+
+// EncryptBIP38 returns the non-EC-multiplied "6P" encrypted key protected by the passphrase
+// see https://github.com/bitcoin/bips/blob/master/bip-0038.mediawiki
+func (pk *PrivateKey) EncryptBIP38(passphrase string) (string, error) {
+	pubkey, err := pk.PublicKey()
+	if err != nil {
+		return "", err
+	}
+
+	addressHash, err := bip38AddressHash(pubkey, pk.Network)
+	if err != nil {
+		return "", err
+	}
+
+	derived, err := scrypt.Key(bip38Passphrase(passphrase), addressHash, 16384, 8, 8, 64)
+	if err != nil {
+		return "", err
+	}
+	defer secp256k1.Zeroize(derived)
+
+	flag := byte(bip38FlagNonEC)
+	if pk.Compressed {
+		flag |= bip38FlagCompressed
+	}
+
+	secret := pk.secretBytes()
+	defer secp256k1.Zeroize(secret)
+
+	data := append([]byte{flag}, addressHash...)
+	data = append(data, bip38Encrypt(derived, secret[:16], 0)...)
+	data = append(data, bip38Encrypt(derived, secret[16:], 16)...)
+
+	return Base58EncodeCheckVersion(data, bip38Version), nil
+}
+
+// DecryptBIP38 decrypts the encrypted key of either mode with the passphrase,
+// the address hash is checked against the P2PKH address on the network
+func DecryptBIP38(encrypted, passphrase string, network Network) (*PrivateKey, error) {
+	data, version, err := Base58DecodeCheckVersion(encrypted, 2)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) != bip38KeySize {
+		return nil, ErrBIP38BadFormat
+	}
+
+	flag, addressHash := data[0], data[1:5]
+	compressed := flag&bip38FlagCompressed != 0
+
+	var secret []byte
+	switch {
+	case bytes.Equal(version, bip38Version):
+		if flag&^bip38FlagCompressed != bip38FlagNonEC {
+			return nil, ErrBIP38BadFormat
+		}
+
+		derived, err := scrypt.Key(bip38Passphrase(passphrase), addressHash, 16384, 8, 8, 64)
+		if err != nil {
+			return nil, err
+		}
+		defer secp256k1.Zeroize(derived)
+
+		secret = append(bip38Decrypt(derived, data[5:21], 0), bip38Decrypt(derived, data[21:37], 16)...)
+	case bytes.Equal(version, bip38ECVersion):
+		if flag&^(bip38FlagCompressed|bip38FlagLotSeq) != 0 {
+			return nil, ErrBIP38BadFormat
+		}
+
+		ownerEntropy := data[5:13]
+		passFactor, err := bip38PassFactor(passphrase, ownerEntropy, flag&bip38FlagLotSeq != 0)
+		if err != nil {
+			return nil, err
+		}
+		defer secp256k1.Zeroize(passFactor)
+
+		passPoint, ok := secp256k1.CreatePubkeyFromBytes(passFactor, true)
+		if !ok {
+			return nil, ErrBIP38BadPassphrase
+		}
+
+		derived, err := scrypt.Key(passPoint, append(append([]byte{}, addressHash...), ownerEntropy...), 1024, 1, 1, 64)
+		if err != nil {
+			return nil, err
+		}
+		defer secp256k1.Zeroize(derived)
+
+		// encryptedpart2 holds the second half of encryptedpart1 and seedb[16:24]
+		part2 := bip38Decrypt(derived, data[21:37], 16)
+		defer secp256k1.Zeroize(part2)
+		part1 := bip38Decrypt(derived, append(append([]byte{}, data[13:21]...), part2[:8]...), 0)
+		defer secp256k1.Zeroize(part1)
+
+		seedB := append(append([]byte{}, part1...), part2[8:]...)
+		defer secp256k1.Zeroize(seedB)
+		factorB := DHash256(seedB).TakeBytes(0, 32)
+		defer secp256k1.Zeroize(factorB)
+
+		if secret, ok = secp256k1.TweakMulSeckey(passFactor, factorB); !ok {
+			return nil, ErrBIP38BadPassphrase
+		}
+	default:
+		return nil, ErrBIP38BadFormat
+	}
+	defer secp256k1.Zeroize(secret)
+
+	pk, err := newPrivateKey(network, secret, compressed)
+	if err != nil {
+		return nil, ErrBIP38BadPassphrase
+	}
+
+	pubkey, err := pk.PublicKey()
+	if err != nil {
+		return nil, err
+	}
+
+	expect, err := bip38AddressHash(pubkey, network)
+	if err != nil {
+		return nil, err
+	}
+
+	if !bytes.Equal(expect, addressHash) {
+		pk.Destroy()
+		return nil, ErrBIP38BadPassphrase
+	}
+
+	return pk, nil
+}
+
+// NewIntermediateCode returns the "passphrase" intermediate code the owner hands to a
+// third party, which generates EC-multiplied keys only the passphrase can decrypt
+func NewIntermediateCode(passphrase string) (string, error) {
+	for i := 0; i < maxRandomAttempts; i++ {
+		ownerSalt := make([]byte, 8)
+		if _, err := rand.Read(ownerSalt); err != nil {
+			return "", err
+		}
+
+		code, err := newIntermediateCode(passphrase, ownerSalt, false)
+		if err != ErrBIP38BadPassphrase {
+			return code, err
+		}
+	}
+
+	return "", ErrBIP38BadPassphrase
+}
+
+// NewIntermediateCodeWithLotSequence is NewIntermediateCode with the lot and sequence
+// numbers embedded in the generated keys and confirmation codes
+func NewIntermediateCodeWithLotSequence(passphrase string, lot, sequence uint32) (string, error) {
+	if lot > BIP38MaxLot || sequence > BIP38MaxSequence {
+		return "", ErrBIP38BadLotSequence
+	}
+
+	for i := 0; i < maxRandomAttempts; i++ {
+		ownerEntropy := make([]byte, 8)
+		if _, err := rand.Read(ownerEntropy[:4]); err != nil {
+			return "", err
+		}
+		binary.BigEndian.PutUint32(ownerEntropy[4:], lot<<12|sequence)
+
+		code, err := newIntermediateCode(passphrase, ownerEntropy, true)
+		if err != ErrBIP38BadPassphrase {
+			return code, err
+		}
+	}
+
+	return "", ErrBIP38BadPassphrase
+}
+
+func newIntermediateCode(passphrase string, ownerEntropy []byte, lotSequence bool) (string, error) {
+	passFactor, err := bip38PassFactor(passphrase, ownerEntropy, lotSequence)
+	if err != nil {
+		return "", err
+	}
+
+	passPoint, ok := secp256k1.CreatePubkeyFromBytes(passFactor, true)
+	if !ok {
+		return "", ErrBIP38BadPassphrase
+	}
+
+	magic := bip38Magic
+	if lotSequence {
+		magic = bip38MagicLotSeq
+	}
+
+	return Base58EncodeCheckVersion(append(append([]byte{}, ownerEntropy...), passPoint...), magic), nil
+}
+
+// NewBIP38KeyFromIntermediate generates a new key from the intermediate code and returns
+// its encrypted key, the confirmation code and the P2PKH address it pays to,
+// the caller learns the address but can not spend from it
+func NewBIP38KeyFromIntermediate(intermediate string, network Network, compressed bool) (string, string, *Address, error) {
+	for i := 0; i < maxRandomAttempts; i++ {
+		seedB := make([]byte, 24)
+		if _, err := rand.Read(seedB); err != nil {
+			return "", "", nil, err
+		}
+
+		encrypted, confirmation, address, err := newBIP38KeyFromIntermediate(intermediate, network, compressed, seedB)
+		secp256k1.Zeroize(seedB)
+		if err != ErrPrivateBadSecret {
+			return encrypted, confirmation, address, err
+		}
+	}
+
+	return "", "", nil, ErrPrivateBadSecret
+}
+
+func newBIP38KeyFromIntermediate(intermediate string, network Network, compressed bool, seedB []byte) (string, string, *Address, error) {
+	data, magic, err := Base58DecodeCheckVersion(intermediate, len(bip38Magic))
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	if len(data) != bip38IntermediateSize {
+		return "", "", nil, ErrBIP38BadFormat
+	}
+
+	flag := byte(0)
+	if compressed {
+		flag |= bip38FlagCompressed
+	}
+
+	switch {
+	case bytes.Equal(magic, bip38Magic):
+	case bytes.Equal(magic, bip38MagicLotSeq):
+		flag |= bip38FlagLotSeq
+	default:
+		return "", "", nil, ErrBIP38BadFormat
+	}
+
+	ownerEntropy, passPoint := data[:8], data[8:]
+
+	factorB := DHash256(seedB).TakeBytes(0, 32)
+	defer secp256k1.Zeroize(factorB)
+
+	if !secp256k1.VerifySeckey(factorB) {
+		return "", "", nil, ErrPrivateBadSecret
+	}
+
+	// the generated public key is factorb * passpoint, its secret is factorb * passfactor
+	generated, ok := secp256k1.TweakMulPubkey(passPoint, factorB)
+	if !ok {
+		return "", "", nil, ErrBIP38BadFormat
+	}
+
+	if !compressed {
+		if generated, ok = secp256k1.ReencodePubkey(generated, false); !ok {
+			return "", "", nil, ErrBIP38BadFormat
+		}
+	}
+
+	address, err := NewPublicKey(generated).Address(network, AddressP2PKH)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	addressHash := checksum([]byte(address.String()))
+	derived, err := scrypt.Key(passPoint, append(append([]byte{}, addressHash...), ownerEntropy...), 1024, 1, 1, 64)
+	if err != nil {
+		return "", "", nil, err
+	}
+	defer secp256k1.Zeroize(derived)
+
+	// encryptedpart2 covers the second half of encryptedpart1 and seedb[16:24]
+	part1 := bip38Encrypt(derived, seedB[:16], 0)
+	block := append(append([]byte{}, part1[8:]...), seedB[16:]...)
+	defer secp256k1.Zeroize(block)
+	part2 := bip38Encrypt(derived, block, 16)
+
+	header := append([]byte{flag}, addressHash...)
+	header = append(header, ownerEntropy...)
+
+	encrypted := append(append([]byte{}, header...), part1[:8]...)
+	encrypted = append(encrypted, part2...)
+
+	pointB, ok := secp256k1.CreatePubkeyFromBytes(factorB, true)
+	if !ok {
+		return "", "", nil, ErrPrivateBadSecret
+	}
+
+	confirmation := append(header, pointB[0]^(derived[63]&0x01))
+	confirmation = append(confirmation, bip38Encrypt(derived, pointB[1:17], 0)...)
+	confirmation = append(confirmation, bip38Encrypt(derived, pointB[17:33], 16)...)
+
+	return Base58EncodeCheckVersion(encrypted, bip38ECVersion),
+		Base58EncodeCheckVersion(confirmation, bip38Confirmation),
+		address, nil
+}
+
+// VerifyBIP38Confirmation checks the confirmation code with the passphrase and returns
+// the P2PKH address on the network of the key it confirms
+func VerifyBIP38Confirmation(confirmation, passphrase string, network Network) (*Address, error) {
+	data, magic, err := Base58DecodeCheckVersion(confirmation, len(bip38Confirmation))
+	if err != nil {
+		return nil, err
+	}
+
+	if !bytes.Equal(magic, bip38Confirmation) || len(data) != bip38ConfirmationSize {
+		return nil, ErrBIP38BadFormat
+	}
+
+	flag, addressHash, ownerEntropy, encryptedPointB := data[0], data[1:5], data[5:13], data[13:46]
+	if flag&^(bip38FlagCompressed|bip38FlagLotSeq) != 0 {
+		return nil, ErrBIP38BadFormat
+	}
+
+	passFactor, err := bip38PassFactor(passphrase, ownerEntropy, flag&bip38FlagLotSeq != 0)
+	if err != nil {
+		return nil, err
+	}
+	defer secp256k1.Zeroize(passFactor)
+
+	passPoint, ok := secp256k1.CreatePubkeyFromBytes(passFactor, true)
+	if !ok {
+		return nil, ErrBIP38BadPassphrase
+	}
+
+	derived, err := scrypt.Key(passPoint, append(append([]byte{}, addressHash...), ownerEntropy...), 1024, 1, 1, 64)
+	if err != nil {
+		return nil, err
+	}
+	defer secp256k1.Zeroize(derived)
+
+	pointB := []byte{encryptedPointB[0] ^ (derived[63] & 0x01)}
+	pointB = append(pointB, bip38Decrypt(derived, encryptedPointB[1:17], 0)...)
+	pointB = append(pointB, bip38Decrypt(derived, encryptedPointB[17:33], 16)...)
+
+	generated, ok := secp256k1.TweakMulPubkey(pointB, passFactor)
+	if !ok {
+		return nil, ErrBIP38BadPassphrase
+	}
+
+	if flag&bip38FlagCompressed == 0 {
+		if generated, ok = secp256k1.ReencodePubkey(generated, false); !ok {
+			return nil, ErrBIP38BadPassphrase
+		}
+	}
+
+	address, err := NewPublicKey(generated).Address(network, AddressP2PKH)
+	if err != nil {
+		return nil, err
+	}
+
+	if !bytes.Equal(checksum([]byte(address.String())), addressHash) {
+		return nil, ErrBIP38BadPassphrase
+	}
+
+	return address, nil
+}
+
+// bip38PassFactor derives the owner's secret factor from the passphrase and owner entropy,
+// the lot and sequence numbers are the last 4 bytes of the owner entropy if present
+func bip38PassFactor(passphrase string, ownerEntropy []byte, lotSequence bool) ([]byte, error) {
+	ownerSalt := ownerEntropy
+	if lotSequence {
+		ownerSalt = ownerEntropy[:4]
+	}
+
+	passFactor, err := scrypt.Key(bip38Passphrase(passphrase), ownerSalt, 16384, 8, 8, 32)
+	if err != nil {
+		return nil, err
+	}
+
+	if lotSequence {
+		passFactor = DHash256(append(passFactor, ownerEntropy...)).TakeBytes(0, 32)
+	}
+
+	return passFactor, nil
+}
+
+// bip38Passphrase returns the NFC normalized passphrase
+func bip38Passphrase(passphrase string) []byte {
+	return []byte(norm.NFC.String(passphrase))
+}
+
+// bip38AddressHash returns the first 4 bytes of SHA256(SHA256(address)) of the P2PKH address
+func bip38AddressHash(pubkey PublicKey, network Network) ([]byte, error) {
+	address, err := pubkey.Address(network, AddressP2PKH)
+	if err != nil {
+		return nil, err
+	}
+
+	return checksum([]byte(address.String())), nil
+}
+
+// bip38Encrypt encrypts the 16-byte block xored with derivedhalf1[offset:offset+16]
+// with AES-256 keyed by derivedhalf2
+func bip38Encrypt(derived, block []byte, offset int) []byte {
+	cipher, _ := aes.NewCipher(derived[32:64])
+
+	out := make([]byte, 16)
+	for i := range out {
+		out[i] = block[i] ^ derived[offset+i]
+	}
+	cipher.Encrypt(out, out)
+
+	return out
+}
+
+// bip38Decrypt reverses bip38Encrypt
+func bip38Decrypt(derived, block []byte, offset int) []byte {
+	cipher, _ := aes.NewCipher(derived[32:64])
+
+	out := make([]byte, 16)
+	cipher.Decrypt(out, block)
+	for i := range out {
+		out[i] ^= derived[offset+i]
+	}
+
+	return out
+}
@@ -0,0 +1,195 @@
+package bcrypto
+
+import (
+	"testing"
+)
+
+func TestBIP38Vectors(t *testing.T) {
+	// the scrypt parameters of BIP38 make these tests slow
+	t.Parallel()
+
+	// https://github.com/bitcoin/bips/blob/master/bip-0038.mediawiki#test-vectors
+	tests := []struct {
+		encrypted  string
+		passphrase string
+		address    string
+		wif        string
+	}{
+		// no compression, no EC multiply
+		{"6PRVWUbkzzsbcVac2qwfssoUJAN1Xhrg6bNk8J7Nzm5H7kxEbn2Nh2ZoGg", "TestingOneTwoThree", "1Jq6MksXQVWzrznvZzxkV6oY57oWXD9TXB", "5KN7MzqK5wt2TP1fQCYyHBtDrXdJuXbUzm4A9rKAteGu3Qi5CVR"},
+		{"6PRNFFkZc2NZ6dJqFfhRoFNMR9Lnyj7dYGrzdgXXVMXcxoKTePPX1dWByq", "Satoshi", "1AvKt49sui9zfzGeo8EyL8ypvAhtR2KwbL", "5HtasZ6ofTHP6HCwTqTkLDuLQisYPah7aUnSKfC7h4hMUVw2gi5"},
+		// GREEK UPSILON WITH HOOK, COMBINING ACUTE ACCENT, NULL, DESERET CAPITAL LETTER LONG I, PILE OF POO
+		{"6PRW5o9FLp4gJDDVqJQKJFTpMvdsSGJxMYHtHaQBF3ooa8mwD69bapcDQn", "ϓ\u0000\U00010400\U0001F4A9", "16ktGzmfrurhbhi6JGqsMWf7TyqK9HNAeF", "5Jajm8eQ22H3pGWLEVCXyvND8dQZhiQhoLJNKjYXk9roUFTMSZ4"},
+		// compression, no EC multiply
+		{"6PYNKZ1EAgYgmQfmNVamxyXVWHzK5s6DGhwP4J5o44cvXdoY7sRzhtpUeo", "TestingOneTwoThree", "164MQi977u9GUteHr4EPH27VkkdxmfCvGW", "L44B5gGEpqEDRS9vVPz7QT35jcBG2r3CZwSwQ4fCewXAhAhqGVpP"},
+		{"6PYLtMnXvfG3oJde97zRyLYFZCYizPU5T3LwgdYJz1fRhh16bU7u6PPmY7", "Satoshi", "1HmPbwsvG5qJ3KJfxzsZRZWhbm1xBMuS8B", "KwYgW8gcxj1JWJXhPSu4Fqwzfhp5Yfi42mdYmMa4XqK7NJxXUSK7"},
+		// EC multiply, no compression, no lot/sequence numbers
+		{"6PfQu77ygVyJLZjfvMLyhLMQbYnu5uguoJJ4kMCLqWwPEdfpwANVS76gTX", "TestingOneTwoThree", "1PE6TQi6HTVNz5DLwB1LcpMBALubfuN2z2", "5K4caxezwjGCGfnoPTZ8tMcJBLB7Jvyjv4xxeacadhq8nLisLR2"},
+		{"6PfLGnQs6VZnrNpmVKfjotbnQuaJK4KZoPFrAjx1JMJUa1Ft8gnf5WxfKd", "Satoshi", "1CqzrtZC6mXSAhoxtFwVjz8LtwLJjDYU3V", "5KJ51SgxWaAYR13zd9ReMhJpwrcX47xTJh2D3fGPG9CM8vkv5sH"},
+		// EC multiply, no compression, lot/sequence numbers
+		{"6PgNBNNzDkKdhkT6uJntUXwwzQV8Rr2tZcbkDcuC9DZRsS6AtHts4Ypo1j", "MOLON LABE", "1Jscj8ALrYu2y9TD8NrpvDBugPedmbj4Yh", "5JLdxTtcTHcfYcmJsNVy1v2PMDx432JPoYcBTVVRHpPaxUrdtf8"},
+		{"6PgGWtx25kUg8QWvwuJAgorN6k9FbE25rv5dMRwu5SKMnfpfVe5mar2ngH", "ΜΟΛΩΝ ΛΑΒΕ", "1Lurmih3KruL4xDB5FmHof38yawNtP9oGf", "5KMKKuUmAkiNbA3DazMQiLfDq47qs8MAEThm4yL8R2PhV1ov33D"},
+	}
+
+	for i, test := range tests {
+		pk, err := DecryptBIP38(test.encrypted, test.passphrase, Mainet)
+		if err != nil {
+			t.Fatalf("%d: %v", i, err)
+		}
+
+		if wif := Base58Encode(pk.Bytes()); wif != test.wif {
+			t.Errorf("%d: expect %s got %s", i, test.wif, wif)
+		}
+
+		address, err := pk.Address(AddressP2PKH)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if address.String() != test.address {
+			t.Errorf("%d: expect %s got %s", i, test.address, address)
+		}
+
+		// only the non-EC-multiplied keys are deterministic
+		if test.encrypted[:3] == "6PR" || test.encrypted[:3] == "6PY" {
+			encrypted, err := pk.EncryptBIP38(test.passphrase)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if encrypted != test.encrypted {
+				t.Errorf("%d: expect %s got %s", i, test.encrypted, encrypted)
+			}
+		}
+	}
+
+	if _, err := DecryptBIP38(tests[0].encrypted, "Satoshi", Mainet); err != ErrBIP38BadPassphrase {
+		t.Errorf("expect ErrBIP38BadPassphrase got %v", err)
+	}
+
+	if _, err := DecryptBIP38(tests[0].encrypted, tests[0].passphrase, Testnet); err != ErrBIP38BadPassphrase {
+		t.Errorf("expect ErrBIP38BadPassphrase got %v", err)
+	}
+
+	if _, err := DecryptBIP38(Base58EncodeCheckVersion(make([]byte, 37), []byte{0x01, 0x44}), "", Mainet); err != ErrBIP38BadFormat {
+		t.Errorf("expect ErrBIP38BadFormat got %v", err)
+	}
+}
+
+func TestBIP38Confirmation(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		intermediate string
+		confirmation string
+		passphrase   string
+		address      string
+	}{
+		{"passphraseaB8feaLQDENqCgr4gKZpmf4VoaT6qdjJNJiv7fsKvjqavcJxvuR1hy25aTu5sX", "cfrm38V8aXBn7JWA1ESmFMUn6erxeBGZGAxJPY4e36S9QWkzZKtaVqLNMgnifETYw7BPwWC9aPD", "MOLON LABE", "1Jscj8ALrYu2y9TD8NrpvDBugPedmbj4Yh"},
+		{"passphrased3z9rQJHSyBkNBwTRPkUGNVEVrUAcfAXDyRU1V28ie6hNFbqDwbFBvsTK7yWVK", "cfrm38V8G4qq2ywYEFfWLD5Cc6msj9UwsG2Mj4Z6QdGJAFQpdatZLavkgRd1i4iBMdRngDqDs51", "ΜΟΛΩΝ ΛΑΒΕ", "1Lurmih3KruL4xDB5FmHof38yawNtP9oGf"},
+	}
+
+	for _, test := range tests {
+		address, err := VerifyBIP38Confirmation(test.confirmation, test.passphrase, Mainet)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if address.String() != test.address {
+			t.Errorf("expect %s got %s", test.address, address)
+		}
+
+		// the intermediate code is determined by the passphrase and the owner entropy
+		data, _, err := Base58DecodeCheckVersion(test.intermediate, len(bip38MagicLotSeq))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		intermediate, err := newIntermediateCode(test.passphrase, data[:8], true)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if intermediate != test.intermediate {
+			t.Errorf("expect %s got %s", test.intermediate, intermediate)
+		}
+	}
+
+	if _, err := VerifyBIP38Confirmation(tests[0].confirmation, "molon labe", Mainet); err != ErrBIP38BadPassphrase {
+		t.Errorf("expect ErrBIP38BadPassphrase got %v", err)
+	}
+}
+
+func TestBIP38ECMultiply(t *testing.T) {
+	t.Parallel()
+
+	passphrase := "TestingOneTwoThree"
+
+	plain, err := NewIntermediateCode(passphrase)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lotSequence, err := NewIntermediateCodeWithLotSequence(passphrase, 263183, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		intermediate string
+		compressed   bool
+	}{
+		{plain, false},
+		{lotSequence, true},
+	}
+
+	for _, test := range tests {
+		if test.intermediate[:10] != "passphrase" {
+			t.Errorf("unexpected intermediate code %s", test.intermediate)
+		}
+
+		encrypted, confirmation, address, err := NewBIP38KeyFromIntermediate(test.intermediate, Mainet, test.compressed)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if encrypted[:2] != "6P" || confirmation[:6] != "cfrm38" {
+			t.Errorf("unexpected encoding %s %s", encrypted, confirmation)
+		}
+
+		confirmed, err := VerifyBIP38Confirmation(confirmation, passphrase, Mainet)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if confirmed.String() != address.String() {
+			t.Errorf("expect %s got %s", address, confirmed)
+		}
+
+		pk, err := DecryptBIP38(encrypted, passphrase, Mainet)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if pk.Compressed != test.compressed {
+			t.Errorf("expect compressed %v", test.compressed)
+		}
+
+		decrypted, err := pk.Address(AddressP2PKH)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if decrypted.String() != address.String() {
+			t.Errorf("expect %s got %s", address, decrypted)
+		}
+	}
+
+	if _, err := NewIntermediateCodeWithLotSequence(passphrase, BIP38MaxLot+1, 0); err != ErrBIP38BadLotSequence {
+		t.Errorf("expect ErrBIP38BadLotSequence got %v", err)
+	}
+
+	if _, _, _, err := NewBIP38KeyFromIntermediate("6PfQu77ygVyJLZjfvMLyhLMQbYnu5uguoJJ4kMCLqWwPEdfpwANVS76gTX", Mainet, false); err != ErrBIP38BadFormat {
+		t.Errorf("expect ErrBIP38BadFormat got %v", err)
+	}
+}
@@ -0,0 +1,217 @@
+package bcrypto
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	// MinEntropyBits is the entropy length of a 12-word mnemonic
+	MinEntropyBits = 128
+	// MaxEntropyBits is the entropy length of a 24-word mnemonic
+	MaxEntropyBits = 256
+	// WordlistSize is the number of words of a BIP39 wordlist
+	WordlistSize = 2048
+
+	// bip39Iterations is the PBKDF2 iteration count of the seed derivation
+	bip39Iterations = 2048
+	// bip39SeedSize is the length of the derived seed
+	bip39SeedSize = 64
+	// bip39WordBits is the number of bits a word encodes
+	bip39WordBits = 11
+)
+
+var (
+	// ErrMnemonicBadEntropy represents the entropy is not 128 to 256 bits in steps of 32
+	ErrMnemonicBadEntropy = errors.New("mnemonic: bad entropy length")
+	// ErrMnemonicBadLength represents the mnemonic has not 12, 15, 18, 21 or 24 words
+	ErrMnemonicBadLength = errors.New("mnemonic: bad number of words")
+	// ErrMnemonicUnknownWord represents a word of the mnemonic is not in the wordlist
+	ErrMnemonicUnknownWord = errors.New("mnemonic: unknown word")
+	// ErrMnemonicBadChecksum represents the checksum bits do not match the entropy
+	ErrMnemonicBadChecksum = errors.New("mnemonic: bad checksum")
+	// ErrWordlistBadFormat represents the wordlist has not 2048 unique words
+	ErrWordlistBadFormat = errors.New("wordlist: bad format")
+)
+
+// MnemonicWordError points at the word of a mnemonic which is not in the wordlist,
+// it matches ErrMnemonicUnknownWord with errors.Is
+type MnemonicWordError struct {
+	// Index is the zero-based position of the word in the mnemonic
+	Index int
+	Word  string
+}
+
+func (e *MnemonicWordError) Error() string {
+	return fmt.Sprintf("%s: %q at position %d", ErrMnemonicUnknownWord, e.Word, e.Index+1)
+}
+
+func (e *MnemonicWordError) Unwrap() error {
+	return ErrMnemonicUnknownWord
+}
+
+// Wordlist is a BIP39 wordlist, the English one is built in and the other
+// official languages are in the wordlists package
+// see https://github.com/bitcoin/bips/blob/master/bip-0039/bip-0039-wordlists.md
+type Wordlist struct {
+	Language string
+	// Separator joins the words of generated mnemonics, Japanese uses the ideographic space
+	Separator string
+
+	words []string
+	index map[string]int
+}
+
+// NewWordlist returns the wordlist of the 2048 words,
+// words are looked up by their NFKD normalized form
+func NewWordlist(language, separator string, words []string) (*Wordlist, error) {
+	if len(words) != WordlistSize {
+		return nil, ErrWordlistBadFormat
+	}
+
+	w := &Wordlist{
+		Language:  language,
+		Separator: separator,
+		words:     append([]string{}, words...),
+		index:     make(map[string]int, WordlistSize),
+	}
+
+	for i, word := range words {
+		key := norm.NFKD.String(word)
+		if _, ok := w.index[key]; ok || key == "" {
+			return nil, ErrWordlistBadFormat
+		}
+		w.index[key] = i
+	}
+
+	return w, nil
+}
+
+func mustWordlist(language, separator, words string) *Wordlist {
+	w, err := NewWordlist(language, separator, strings.Fields(words))
+	if err != nil {
+		panic(language + ": " + err.Error())
+	}
+
+	return w
+}
+
+// Word returns the word at index, which must be below WordlistSize
+func (w *Wordlist) Word(index int) string {
+	return w.words[index]
+}
+
+// Index returns the index of the word, ignoring its unicode normalization form
+func (w *Wordlist) Index(word string) (int, bool) {
+	index, ok := w.index[norm.NFKD.String(word)]
+	return index, ok
+}
+
+// NewEntropy returns random entropy of the bit size for NewMnemonic
+func NewEntropy(bits int) ([]byte, error) {
+	if !validEntropyBits(bits) {
+		return nil, ErrMnemonicBadEntropy
+	}
+
+	entropy := make([]byte, bits/8)
+	if _, err := rand.Read(entropy); err != nil {
+		return nil, err
+	}
+
+	return entropy, nil
+}
+
+func validEntropyBits(bits int) bool {
+	return bits >= MinEntropyBits && bits <= MaxEntropyBits && bits%32 == 0
+}
+
+// NewMnemonic encodes the entropy and its SHA256 checksum as words of the wordlist
+// see https://github.com/bitcoin/bips/blob/master/bip-0039.mediawiki#generating-the-mnemonic
+func NewMnemonic(entropy []byte, wordlist *Wordlist) (string, error) {
+	bits := len(entropy) * 8
+	if !validEntropyBits(bits) {
+		return "", ErrMnemonicBadEntropy
+	}
+
+	hash := sha256.Sum256(entropy)
+	data := append(append([]byte{}, entropy...), hash[0])
+
+	words := make([]string, (bits+bits/32)/bip39WordBits)
+	for i := range words {
+		index := 0
+		for j := i * bip39WordBits; j < (i+1)*bip39WordBits; j++ {
+			index = index<<1 | int(data[j/8]>>(7-j%8)&1)
+		}
+		words[i] = wordlist.Word(index)
+	}
+
+	return strings.Join(words, wordlist.Separator), nil
+}
+
+// MnemonicToEntropy decodes the mnemonic back to its entropy and checks the checksum,
+// an unknown word is reported as a *MnemonicWordError
+func MnemonicToEntropy(mnemonic string, wordlist *Wordlist) ([]byte, error) {
+	words := strings.Fields(mnemonic)
+	switch len(words) {
+	case 12, 15, 18, 21, 24:
+	default:
+		return nil, ErrMnemonicBadLength
+	}
+
+	data := make([]byte, (len(words)*bip39WordBits+7)/8)
+	for i, word := range words {
+		index, ok := wordlist.Index(word)
+		if !ok {
+			return nil, &MnemonicWordError{Index: i, Word: word}
+		}
+
+		for j := 0; j < bip39WordBits; j++ {
+			if index&(1<<uint(bip39WordBits-1-j)) != 0 {
+				bit := i*bip39WordBits + j
+				data[bit/8] |= 0x80 >> uint(bit%8)
+			}
+		}
+	}
+
+	bits := len(words) * bip39WordBits * 32 / 33
+	entropy := data[:bits/8]
+	hash := sha256.Sum256(entropy)
+
+	shift := uint(8 - bits/32)
+	if data[bits/8]>>shift != hash[0]>>shift {
+		return nil, ErrMnemonicBadChecksum
+	}
+
+	return entropy, nil
+}
+
+// ValidateMnemonic checks the words and the checksum of the mnemonic
+func ValidateMnemonic(mnemonic string, wordlist *Wordlist) error {
+	_, err := MnemonicToEntropy(mnemonic, wordlist)
+	return err
+}
+
+// NewSeed derives the 64-byte seed with PBKDF2-HMAC-SHA512 of the NFKD normalized
+// mnemonic and "mnemonic" + passphrase, the mnemonic is not validated
+// see https://github.com/bitcoin/bips/blob/master/bip-0039.mediawiki#from-mnemonic-to-seed
+func NewSeed(mnemonic, passphrase string) []byte {
+	password := []byte(norm.NFKD.String(mnemonic))
+	salt := []byte(norm.NFKD.String("mnemonic" + passphrase))
+	return pbkdf2.Key(password, salt, bip39Iterations, bip39SeedSize, sha512.New)
+}
+
+// NewSeedFromMnemonic validates the mnemonic against the wordlist and derives its seed
+func NewSeedFromMnemonic(mnemonic, passphrase string, wordlist *Wordlist) ([]byte, error) {
+	if err := ValidateMnemonic(mnemonic, wordlist); err != nil {
+		return nil, err
+	}
+
+	return NewSeed(mnemonic, passphrase), nil
+}
@@ -0,0 +1,222 @@
+package bcrypto
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrPathBadFormat represents the derivation path can not be parsed
+	ErrPathBadFormat = errors.New("derivation path: bad format")
+	// ErrPathBadIndex represents a path index is not below 2^31
+	ErrPathBadIndex = errors.New("derivation path: index out of range")
+	// ErrPurposeUnknown represents the purpose has no address type
+	ErrPurposeUnknown = errors.New("unknown purpose")
+)
+
+// DerivationPath is the list of BIP32 child indexes from the master key
+type DerivationPath []uint32
+
+// ParseDerivationPath parses paths like m/84'/0'/0'/0/5,
+// hardened indexes are marked with ' or h and the leading m is optional
+func ParseDerivationPath(str string) (DerivationPath, error) {
+	str = strings.TrimSpace(str)
+	if str == "m" || str == "" {
+		return DerivationPath{}, nil
+	}
+
+	str = strings.TrimPrefix(str, "m/")
+
+	parts := strings.Split(str, "/")
+	path := make(DerivationPath, 0, len(parts))
+	for _, part := range parts {
+		var offset uint32
+		if strings.HasSuffix(part, "'") || strings.HasSuffix(part, "h") || strings.HasSuffix(part, "H") {
+			part = part[:len(part)-1]
+			offset = HardenedKeyStart
+		}
+
+		if part == "" || part[0] == '+' || part[0] == '-' {
+			return nil, ErrPathBadFormat
+		}
+
+		index, err := strconv.ParseUint(part, 10, 32)
+		if err != nil {
+			if errors.Is(err, strconv.ErrRange) {
+				return nil, ErrPathBadIndex
+			}
+			return nil, ErrPathBadFormat
+		}
+
+		if index >= HardenedKeyStart {
+			return nil, ErrPathBadIndex
+		}
+
+		path = append(path, uint32(index)+offset)
+	}
+
+	return path, nil
+}
+
+// Child returns a copy of the path extended with the index
+func (p DerivationPath) Child(index uint32) DerivationPath {
+	path := make(DerivationPath, len(p), len(p)+1)
+	copy(path, p)
+	return append(path, index)
+}
+
+// String prints the path with the ' hardened notation
+func (p DerivationPath) String() string {
+	var sb strings.Builder
+	sb.WriteString("m")
+	for _, index := range p {
+		sb.WriteByte('/')
+		if index >= HardenedKeyStart {
+			sb.WriteString(strconv.FormatUint(uint64(index-HardenedKeyStart), 10))
+			sb.WriteByte('\'')
+		} else {
+			sb.WriteString(strconv.FormatUint(uint64(index), 10))
+		}
+	}
+
+	return sb.String()
+}
+
+// Derive derives the descendant at the path relative to the key,
+// the intermediate keys are destroyed
+func (k *ExtendedKey) Derive(path DerivationPath) (*ExtendedKey, error) {
+	key := k
+	for _, index := range path {
+		child, err := key.Child(index)
+		if key != k {
+			key.Destroy()
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		key = child
+	}
+
+	return key, nil
+}
+
+// Purpose is the first level of the BIP43 derivation path
+type Purpose uint32
+
+const (
+	// PurposeBIP44 derives P2PKH addresses
+	PurposeBIP44 Purpose = 44
+	// PurposeBIP49 derives P2SH-P2WPKH addresses
+	PurposeBIP49 Purpose = 49
+	// PurposeBIP84 derives P2WPKH addresses
+	PurposeBIP84 Purpose = 84
+	// PurposeBIP86 derives single key P2TR addresses
+	PurposeBIP86 Purpose = 86
+)
+
+// AddressType returns the address kind derived under the purpose
+func (p Purpose) AddressType() (AddressType, error) {
+	switch p {
+	case PurposeBIP44:
+		return AddressP2PKH, nil
+	case PurposeBIP49:
+		return AddressP2SH, nil
+	case PurposeBIP84:
+		return AddressP2WPKH, nil
+	case PurposeBIP86:
+		return AddressP2TR, nil
+	}
+
+	return 0, ErrPurposeUnknown
+}
+
+// PurposeByAddressType returns the purpose deriving the address kind
+func PurposeByAddressType(kind AddressType) (Purpose, error) {
+	switch kind {
+	case AddressP2PKH:
+		return PurposeBIP44, nil
+	case AddressP2SH:
+		return PurposeBIP49, nil
+	case AddressP2WPKH:
+		return PurposeBIP84, nil
+	case AddressP2TR:
+		return PurposeBIP86, nil
+	}
+
+	return 0, ErrPurposeUnknown
+}
+
+// AccountPath returns m/purpose'/coin_type'/account' with the coin type of the network
+func (p Purpose) AccountPath(network Network, account uint32) (DerivationPath, error) {
+	if _, err := p.AddressType(); err != nil {
+		return nil, err
+	}
+
+	if account >= HardenedKeyStart {
+		return nil, ErrPathBadIndex
+	}
+
+	params, err := network.Params()
+	if err != nil {
+		return nil, err
+	}
+
+	if params.HDCoinType >= HardenedKeyStart {
+		return nil, ErrPathBadIndex
+	}
+
+	return DerivationPath{
+		uint32(p) + HardenedKeyStart,
+		params.HDCoinType + HardenedKeyStart,
+		account + HardenedKeyStart,
+	}, nil
+}
+
+// AddressPath returns m/purpose'/coin_type'/account'/change/index,
+// change selects the internal chain
+func (p Purpose) AddressPath(network Network, account uint32, change bool, index uint32) (DerivationPath, error) {
+	path, err := p.AccountPath(network, account)
+	if err != nil {
+		return nil, err
+	}
+
+	if index >= HardenedKeyStart {
+		return nil, ErrPathBadIndex
+	}
+
+	var chain uint32
+	if change {
+		chain = 1
+	}
+
+	return append(path, chain, index), nil
+}
+
+// DeriveAddress derives the address at m/purpose'/coin_type'/account'/change/index
+// of the master key, with the address kind of the purpose, ErrHDNotMaster if k is not at depth 0
+func (k *ExtendedKey) DeriveAddress(purpose Purpose, account uint32, change bool, index uint32) (*Address, error) {
+	if k.Depth != 0 {
+		return nil, ErrHDNotMaster
+	}
+
+	kind, err := purpose.AddressType()
+	if err != nil {
+		return nil, err
+	}
+
+	path, err := purpose.AddressPath(k.Network, account, change, index)
+	if err != nil {
+		return nil, err
+	}
+
+	child, err := k.Derive(path)
+	if err != nil {
+		return nil, err
+	}
+	defer child.Destroy()
+
+	return child.Address(kind)
+}
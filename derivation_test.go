@@ -0,0 +1,139 @@
+package bcrypto
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+func TestDerivationPath(t *testing.T) {
+	tests := []struct {
+		input  string
+		path   DerivationPath
+		expect string
+	}{
+		{"m", DerivationPath{}, "m"},
+		{"m/0", DerivationPath{0}, "m/0"},
+		{"m/84'/0'/0'/0/5", DerivationPath{HardenedKeyStart + 84, HardenedKeyStart, HardenedKeyStart, 0, 5}, "m/84'/0'/0'/0/5"},
+		{"m/84h/0h/0h/1/2", DerivationPath{HardenedKeyStart + 84, HardenedKeyStart, HardenedKeyStart, 1, 2}, "m/84'/0'/0'/1/2"},
+		{"44H/1'/2147483647", DerivationPath{HardenedKeyStart + 44, HardenedKeyStart + 1, 2147483647}, "m/44'/1'/2147483647"},
+	}
+
+	for _, test := range tests {
+		path, err := ParseDerivationPath(test.input)
+		if err != nil {
+			t.Fatalf("%s: %v", test.input, err)
+		}
+
+		if path.String() != test.expect {
+			t.Errorf("expect %s got %s", test.expect, path)
+		}
+
+		if len(path) != len(test.path) {
+			t.Fatalf("expect %v got %v", test.path, path)
+		}
+
+		for i := range path {
+			if path[i] != test.path[i] {
+				t.Errorf("expect %v got %v", test.path, path)
+			}
+		}
+	}
+
+	invalid := []struct {
+		input  string
+		expect error
+	}{
+		{"m/", ErrPathBadFormat},
+		{"m/a", ErrPathBadFormat},
+		{"m/0''", ErrPathBadFormat},
+		{"m/-1", ErrPathBadFormat},
+		{"m/1//2", ErrPathBadFormat},
+		{"m/2147483648", ErrPathBadIndex},
+		{"m/2147483648'", ErrPathBadIndex},
+		{"m/4294967296", ErrPathBadIndex},
+	}
+
+	for _, test := range invalid {
+		if _, err := ParseDerivationPath(test.input); err != test.expect {
+			t.Errorf("%s: expect %v got %v", test.input, test.expect, err)
+		}
+	}
+}
+
+func TestDeriveAddress(t *testing.T) {
+	// seed of the mnemonic "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+	seed, _ := hex.DecodeString("5eb00bbddcf069084889a8ab9155568165f5c453ccb85e70811aaed6f6da5fc19a5ac40b389cd370d086206dec8aa6c43daea6690f20ad3d8d48b2d2ce9e38e4")
+
+	tests := []struct {
+		network Network
+		purpose Purpose
+		change  bool
+		index   uint32
+		path    string
+		expect  string
+	}{
+		{Mainet, PurposeBIP44, false, 0, "m/44'/0'/0'/0/0", "1LqBGSKuX5yYUonjxT5qGfpUsXKYYWeabA"},
+		{Testnet, PurposeBIP49, false, 0, "m/49'/1'/0'/0/0", "2Mww8dCYPUpKHofjgcXcBCEGmniw9CoaiD2"},
+		{Mainet, PurposeBIP84, false, 0, "m/84'/0'/0'/0/0", "bc1qcr8te4kr609gcawutmrza0j4xv80jy8z306fyu"},
+		{Mainet, PurposeBIP84, false, 1, "m/84'/0'/0'/0/1", "bc1qnjg0jd8228aq7egyzacy8cys3knf9xvrerkf9g"},
+		{Mainet, PurposeBIP84, true, 0, "m/84'/0'/0'/1/0", "bc1q8c6fshw2dlwun7ekn9qwf37cu2rn755upcp6el"},
+		{Mainet, PurposeBIP86, false, 0, "m/86'/0'/0'/0/0", "bc1p5cyxnuxmeuwuvkwfem96lqzszd02n6xdcjrs20cac6yqjjwudpxqkedrcr"},
+	}
+
+	for _, test := range tests {
+		master, err := NewMasterKey(test.network, seed)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		path, err := test.purpose.AddressPath(test.network, 0, test.change, test.index)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if path.String() != test.path {
+			t.Errorf("expect %s got %s", test.path, path)
+		}
+
+		address, err := master.DeriveAddress(test.purpose, 0, test.change, test.index)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if address.String() != test.expect {
+			t.Errorf("expect %s got %s", test.expect, address)
+		}
+
+		purpose, err := PurposeByAddressType(address.Kind)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if purpose != test.purpose {
+			t.Errorf("expect purpose %d got %d", test.purpose, purpose)
+		}
+	}
+
+	master, err := NewMasterKey(Mainet, seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	account, err := master.Derive(DerivationPath{84 + HardenedKeyStart, HardenedKeyStart, HardenedKeyStart})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the path would be derived below the account key rather than the master key
+	if _, err := account.DeriveAddress(PurposeBIP84, 0, false, 0); err != ErrHDNotMaster {
+		t.Errorf("expect ErrHDNotMaster got %v", err)
+	}
+
+	if _, err := Purpose(0).AccountPath(Mainet, 0); err != ErrPurposeUnknown {
+		t.Errorf("expect ErrPurposeUnknown got %v", err)
+	}
+
+	if _, err := PurposeBIP84.AddressPath(Mainet, HardenedKeyStart, false, 0); err != ErrPathBadIndex {
+		t.Errorf("expect ErrPathBadIndex got %v", err)
+	}
+}
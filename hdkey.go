@@ -0,0 +1,395 @@
+package bcrypto
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/detailyang/go-bcrypto/secp256k1"
+	. "github.com/detailyang/go-bprimitives"
+)
+
+const (
+	// HardenedKeyStart is the index of the first hardened child
+	HardenedKeyStart = 0x80000000
+	// MinSeedBytes is the minimum seed length allowed by BIP32
+	MinSeedBytes = 16
+	// MaxSeedBytes is the maximum seed length allowed by BIP32
+	MaxSeedBytes = 64
+	// RecommendedSeedLen is the seed length recommended by BIP32
+	RecommendedSeedLen = 32
+
+	// extendedKeySize is the length of a serialized extended key without checksum
+	extendedKeySize = 78
+)
+
+var masterKey = []byte("Bitcoin seed")
+
+var (
+	// ErrHDBadSeedLength represents the seed is shorter than 16 or longer than 64 bytes
+	ErrHDBadSeedLength = errors.New("hdkey: bad seed length")
+	// ErrHDUnusableSeed represents the seed derives an invalid master key
+	ErrHDUnusableSeed = errors.New("hdkey: unusable seed")
+	// ErrHDInvalidChild represents the child key is invalid, the next index should be used
+	ErrHDInvalidChild = errors.New("hdkey: invalid child")
+	// ErrHDDeriveHardenedFromPublic represents a hardened child is derived from a public key
+	ErrHDDeriveHardenedFromPublic = errors.New("hdkey: cannot derive a hardened child from a public key")
+	// ErrHDDepthExceeded represents the key is already at depth 255
+	ErrHDDepthExceeded = errors.New("hdkey: depth exceeded")
+	// ErrHDNotPrivate represents a private key is requested from a public extended key
+	ErrHDNotPrivate = errors.New("hdkey: not a private extended key")
+	// ErrHDBadFormat represents the serialized extended key has a bad length or bad fields
+	ErrHDBadFormat = errors.New("hdkey: bad format")
+	// ErrHDBadKey represents the key data is not a valid secret or point
+	ErrHDBadKey = errors.New("hdkey: bad key")
+	// ErrHDNotMaster represents a master key is required but the key is at a nonzero depth
+	ErrHDNotMaster = errors.New("hdkey: not a master key")
+)
+
+// ExtendedKey is a BIP32 private or public extended key
+// see https://github.com/bitcoin/bips/blob/master/bip-0032.mediawiki
+type ExtendedKey struct {
+	Network           Network
+	Depth             byte
+	ParentFingerprint [4]byte
+	ChildIndex        uint32
+	ChainCode         []byte
+	// Key is the 32-byte secret of private keys or the 33-byte compressed point of public keys
+	Key     []byte
+	Private bool
+	// ScriptType selects the SLIP-132 version the key is serialized with
+	ScriptType ScriptType
+}
+
+// NewMasterKey derives the master private key from the seed
+func NewMasterKey(network Network, seed []byte) (*ExtendedKey, error) {
+	if len(seed) < MinSeedBytes || len(seed) > MaxSeedBytes {
+		return nil, ErrHDBadSeedLength
+	}
+
+	mac := hmac.New(sha512.New, masterKey)
+	mac.Write(seed)
+	sum := mac.Sum(nil)
+	defer secp256k1.Zeroize(sum)
+
+	if !secp256k1.VerifySeckey(sum[:32]) {
+		return nil, ErrHDUnusableSeed
+	}
+
+	return &ExtendedKey{
+		Network:   network,
+		ChainCode: append([]byte{}, sum[32:]...),
+		Key:       append([]byte{}, sum[:32]...),
+		Private:   true,
+	}, nil
+}
+
+// Child derives the child at index, indexes from HardenedKeyStart are hardened.
+// Private keys derive private children and public keys derive public children.
+func (k *ExtendedKey) Child(index uint32) (*ExtendedKey, error) {
+	if k.Depth == 0xff {
+		return nil, ErrHDDepthExceeded
+	}
+
+	hardened := index >= HardenedKeyStart
+	if hardened && !k.Private {
+		return nil, ErrHDDeriveHardenedFromPublic
+	}
+
+	pubkey, err := k.pubkeyBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	var data []byte
+	if hardened {
+		data = append([]byte{0x00}, k.Key...)
+		defer secp256k1.Zeroize(data)
+	} else {
+		data = pubkey
+	}
+
+	var ser [4]byte
+	binary.BigEndian.PutUint32(ser[:], index)
+
+	mac := hmac.New(sha512.New, k.ChainCode)
+	mac.Write(data)
+	mac.Write(ser[:])
+	sum := mac.Sum(nil)
+	defer secp256k1.Zeroize(sum)
+
+	var key []byte
+	var ok bool
+	if k.Private {
+		// IL + k mod n, fails if IL >= n or the sum is zero
+		key, ok = secp256k1.TweakAddSeckey(k.Key, sum[:32])
+	} else {
+		// point(IL) + K, fails if IL >= n or the sum is infinity
+		key, ok = secp256k1.TweakAddPubkey(k.Key, sum[:32])
+	}
+
+	if !ok {
+		return nil, ErrHDInvalidChild
+	}
+
+	child := &ExtendedKey{
+		Network:    k.Network,
+		Depth:      k.Depth + 1,
+		ChildIndex: index,
+		ChainCode:  append([]byte{}, sum[32:]...),
+		Key:        key,
+		Private:    k.Private,
+		ScriptType: k.ScriptType,
+	}
+	copy(child.ParentFingerprint[:], Hash160(pubkey)[:4])
+
+	return child, nil
+}
+
+// Neuter returns the public extended key of the key
+func (k *ExtendedKey) Neuter() (*ExtendedKey, error) {
+	if !k.Private {
+		return k, nil
+	}
+
+	pubkey, err := k.pubkeyBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ExtendedKey{
+		Network:           k.Network,
+		Depth:             k.Depth,
+		ParentFingerprint: k.ParentFingerprint,
+		ChildIndex:        k.ChildIndex,
+		ChainCode:         append([]byte{}, k.ChainCode...),
+		Key:               pubkey,
+		ScriptType:        k.ScriptType,
+	}, nil
+}
+
+// IsHardened reports whether the key is a hardened child
+func (k *ExtendedKey) IsHardened() bool {
+	return k.ChildIndex >= HardenedKeyStart
+}
+
+// Fingerprint returns the first 4 bytes of the hash160 of the public key
+func (k *ExtendedKey) Fingerprint() ([4]byte, error) {
+	var fp [4]byte
+
+	pubkey, err := k.pubkeyBytes()
+	if err != nil {
+		return fp, err
+	}
+
+	copy(fp[:], Hash160(pubkey)[:4])
+	return fp, nil
+}
+
+// PublicKey returns the compressed public key
+func (k *ExtendedKey) PublicKey() (PublicKey, error) {
+	pubkey, err := k.pubkeyBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewPublicKey(pubkey), nil
+}
+
+// PrivateKey returns the compressed private key of a private extended key
+func (k *ExtendedKey) PrivateKey() (*PrivateKey, error) {
+	if !k.Private {
+		return nil, ErrHDNotPrivate
+	}
+
+	return newPrivateKey(k.Network, k.Key, true)
+}
+
+// Address derives the address of the kind paying to the public key
+func (k *ExtendedKey) Address(kind AddressType) (*Address, error) {
+	pubkey, err := k.PublicKey()
+	if err != nil {
+		return nil, err
+	}
+
+	return pubkey.Address(k.Network, kind)
+}
+
+// Destroy wipes the key data and the chain code, copies made by WithScriptType
+// share them and are wiped too
+func (k *ExtendedKey) Destroy() {
+	secp256k1.Zeroize(k.Key)
+	secp256k1.Zeroize(k.ChainCode)
+	k.Key = nil
+	k.ChainCode = nil
+}
+
+func (k *ExtendedKey) pubkeyBytes() ([]byte, error) {
+	if !k.Private {
+		return k.Key, nil
+	}
+
+	if len(k.Key) != 32 {
+		return nil, ErrHDBadKey
+	}
+
+	pubkey, ok := secp256k1.CreatePubkeyFromBytes(k.Key, true)
+	if !ok {
+		return nil, ErrHDBadKey
+	}
+
+	return pubkey, nil
+}
+
+// Bytes returns the 78-byte serialization without checksum,
+// the version is picked by the network and the script type
+func (k *ExtendedKey) Bytes() ([]byte, error) {
+	versions, err := HDVersionOf(k.Network, k.ScriptType)
+	if err != nil {
+		return nil, err
+	}
+
+	version := versions.PublicKeyID
+	if k.Private {
+		version = versions.PrivateKeyID
+	}
+
+	return k.serialize(version), nil
+}
+
+func (k *ExtendedKey) serialize(version [4]byte) []byte {
+	var buf bytes.Buffer
+	buf.Write(version[:])
+	buf.WriteByte(k.Depth)
+	buf.Write(k.ParentFingerprint[:])
+
+	var index [4]byte
+	binary.BigEndian.PutUint32(index[:], k.ChildIndex)
+	buf.Write(index[:])
+	buf.Write(k.ChainCode)
+
+	if k.Private {
+		buf.WriteByte(0x00)
+	}
+	buf.Write(k.Key)
+
+	return buf.Bytes()
+}
+
+// Encode returns the base58check xprv/xpub string, or its SLIP-132 form such as zprv/zpub
+func (k *ExtendedKey) Encode() (string, error) {
+	data, err := k.Bytes()
+	if err != nil {
+		return "", err
+	}
+	defer secp256k1.Zeroize(data)
+
+	return Base58EncodeCheckVersion(data[4:], data[:4]), nil
+}
+
+// String returns the xpub string of public keys and redacts private keys,
+// use Encode to serialize a private key
+//
+// String, GoString and Format have value receivers so that ExtendedKey values and
+// struct fields holding them are redacted too
+func (k ExtendedKey) String() string {
+	if k.Private {
+		return fmt.Sprintf("ExtendedKey(%s, depth %d, [REDACTED])", k.Network, k.Depth)
+	}
+
+	str, err := k.Encode()
+	if err != nil {
+		return fmt.Sprintf("ExtendedKey(invalid: %v)", err)
+	}
+
+	return str
+}
+
+// GoString redacts the key data of private keys for %#v
+func (k ExtendedKey) GoString() string {
+	key := "[REDACTED]"
+	if !k.Private {
+		key = hex.EncodeToString(k.Key)
+	}
+
+	return fmt.Sprintf("bcrypto.ExtendedKey{Network: %s, Depth: %d, Private: %t, Key: %s}", k.Network, k.Depth, k.Private, key)
+}
+
+// Format implements fmt.Formatter so that every verb, %x and %d included, is redacted
+func (k ExtendedKey) Format(f fmt.State, verb rune) {
+	formatRedacted(f, verb, k.String(), k.GoString())
+}
+
+// ParseExtendedKey decodes the base58check xprv/xpub string or its SLIP-132 forms,
+// the network and the script type are looked up by the version bytes
+func ParseExtendedKey(str string) (*ExtendedKey, error) {
+	payload, versionBytes, err := Base58DecodeCheckVersion(str, 4)
+	if err == ErrInvalidFormat {
+		return nil, ErrHDBadFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	defer secp256k1.Zeroize(payload)
+
+	if len(payload) != extendedKeySize-4 {
+		return nil, ErrHDBadFormat
+	}
+
+	var version [4]byte
+	copy(version[:], versionBytes)
+
+	network, script, private, err := NetworkByHDVersion(version)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseExtendedKey(payload, network, script, private)
+}
+
+// parseExtendedKey decodes the serialization following the version bytes
+func parseExtendedKey(payload []byte, network Network, script ScriptType, private bool) (*ExtendedKey, error) {
+	k := &ExtendedKey{
+		Network:    network,
+		Depth:      payload[0],
+		ChildIndex: binary.BigEndian.Uint32(payload[5:9]),
+		ChainCode:  append([]byte{}, payload[9:41]...),
+		Private:    private,
+		ScriptType: script,
+	}
+	copy(k.ParentFingerprint[:], payload[1:5])
+
+	if k.Depth == 0 && (k.ParentFingerprint != [4]byte{} || k.ChildIndex != 0) {
+		return nil, ErrHDBadFormat
+	}
+
+	keyData := payload[41:]
+	if private {
+		if keyData[0] != 0x00 {
+			return nil, ErrHDBadKey
+		}
+
+		k.Key = append([]byte{}, keyData[1:]...)
+		if !secp256k1.VerifySeckey(k.Key) {
+			k.Destroy()
+			return nil, ErrHDBadKey
+		}
+	} else {
+		if keyData[0] != 0x02 && keyData[0] != 0x03 {
+			return nil, ErrHDBadKey
+		}
+
+		if _, ok := secp256k1.ReencodePubkey(keyData, true); !ok {
+			return nil, ErrHDBadKey
+		}
+
+		k.Key = append([]byte{}, keyData...)
+	}
+
+	return k, nil
+}
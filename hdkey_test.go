@@ -0,0 +1,328 @@
+package bcrypto
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// https://github.com/bitcoin/bips/blob/master/bip-0032.mediawiki#test-vectors
+func TestExtendedKeyVectors(t *testing.T) {
+	tests := []struct {
+		seed string
+		path []uint32
+		// public and private keys of m and each step of the path
+		keys [][2]string
+	}{
+		{
+			"000102030405060708090a0b0c0d0e0f",
+			[]uint32{HardenedKeyStart + 0, 1, HardenedKeyStart + 2, 2, 1000000000},
+			[][2]string{
+				{"xpub661MyMwAqRbcFtXgS5sYJABqqG9YLmC4Q1Rdap9gSE8NqtwybGhePY2gZ29ESFjqJoCu1Rupje8YtGqsefD265TMg7usUDFdp6W1EGMcet8", "xprv9s21ZrQH143K3QTDL4LXw2F7HEK3wJUD2nW2nRk4stbPy6cq3jPPqjiChkVvvNKmPGJxWUtg6LnF5kejMRNNU3TGtRBeJgk33yuGBxrMPHi"},
+				{"xpub68Gmy5EdvgibQVfPdqkBBCHxA5htiqg55crXYuXoQRKfDBFA1WEjWgP6LHhwBZeNK1VTsfTFUHCdrfp1bgwQ9xv5ski8PX9rL2dZXvgGDnw", "xprv9uHRZZhk6KAJC1avXpDAp4MDc3sQKNxDiPvvkX8Br5ngLNv1TxvUxt4cV1rGL5hj6KCesnDYUhd7oWgT11eZG7XnxHrnYeSvkzY7d2bhkJ7"},
+				{"xpub6ASuArnXKPbfEwhqN6e3mwBcDTgzisQN1wXN9BJcM47sSikHjJf3UFHKkNAWbWMiGj7Wf5uMash7SyYq527Hqck2AxYysAA7xmALppuCkwQ", "xprv9wTYmMFdV23N2TdNG573QoEsfRrWKQgWeibmLntzniatZvR9BmLnvSxqu53Kw1UmYPxLgboyZQaXwTCg8MSY3H2EU4pWcQDnRnrVA1xe8fs"},
+				{"xpub6D4BDPcP2GT577Vvch3R8wDkScZWzQzMMUm3PWbmWvVJrZwQY4VUNgqFJPMM3No2dFDFGTsxxpG5uJh7n7epu4trkrX7x7DogT5Uv6fcLW5", "xprv9z4pot5VBttmtdRTWfWQmoH1taj2axGVzFqSb8C9xaxKymcFzXBDptWmT7FwuEzG3ryjH4ktypQSAewRiNMjANTtpgP4mLTj34bhnZX7UiM"},
+				{"xpub6FHa3pjLCk84BayeJxFW2SP4XRrFd1JYnxeLeU8EqN3vDfZmbqBqaGJAyiLjTAwm6ZLRQUMv1ZACTj37sR62cfN7fe5JnJ7dh8zL4fiyLHV", "xprvA2JDeKCSNNZky6uBCviVfJSKyQ1mDYahRjijr5idH2WwLsEd4Hsb2Tyh8RfQMuPh7f7RtyzTtdrbdqqsunu5Mm3wDvUAKRHSC34sJ7in334"},
+				{"xpub6H1LXWLaKsWFhvm6RVpEL9P4KfRZSW7abD2ttkWP3SSQvnyA8FSVqNTEcYFgJS2UaFcxupHiYkro49S8yGasTvXEYBVPamhGW6cFJodrTHy", "xprvA41z7zogVVwxVSgdKUHDy1SKmdb533PjDz7J6N6mV6uS3ze1ai8FHa8kmHScGpWmj4WggLyQjgPie1rFSruoUihUZREPSL39UNdE3BBDu76"},
+			},
+		},
+		{
+			"fffcf9f6f3f0edeae7e4e1dedbd8d5d2cfccc9c6c3c0bdbab7b4b1aeaba8a5a29f9c999693908d8a8784817e7b7875726f6c696663605d5a5754514e4b484542",
+			[]uint32{0, HardenedKeyStart + 2147483647, 1, HardenedKeyStart + 2147483646, 2},
+			[][2]string{
+				{"xpub661MyMwAqRbcFW31YEwpkMuc5THy2PSt5bDMsktWQcFF8syAmRUapSCGu8ED9W6oDMSgv6Zz8idoc4a6mr8BDzTJY47LJhkJ8UB7WEGuduB", "xprv9s21ZrQH143K31xYSDQpPDxsXRTUcvj2iNHm5NUtrGiGG5e2DtALGdso3pGz6ssrdK4PFmM8NSpSBHNqPqm55Qn3LqFtT2emdEXVYsCzC2U"},
+				{"xpub69H7F5d8KSRgmmdJg2KhpAK8SR3DjMwAdkxj3ZuxV27CprR9LgpeyGmXUbC6wb7ERfvrnKZjXoUmmDznezpbZb7ap6r1D3tgFxHmwMkQTPH", "xprv9vHkqa6EV4sPZHYqZznhT2NPtPCjKuDKGY38FBWLvgaDx45zo9WQRUT3dKYnjwih2yJD9mkrocEZXo1ex8G81dwSM1fwqWpWkeS3v86pgKt"},
+				{"xpub6ASAVgeehLbnwdqV6UKMHVzgqAG8Gr6riv3Fxxpj8ksbH9ebxaEyBLZ85ySDhKiLDBrQSARLq1uNRts8RuJiHjaDMBU4Zn9h8LZNnBC5y4a", "xprv9wSp6B7kry3Vj9m1zSnLvN3xH8RdsPP1Mh7fAaR7aRLcQMKTR2vidYEeEg2mUCTAwCd6vnxVrcjfy2kRgVsFawNzmjuHc2YmYRmagcEPdU9"},
+				{"xpub6DF8uhdarytz3FWdA8TvFSvvAh8dP3283MY7p2V4SeE2wyWmG5mg5EwVvmdMVCQcoNJxGoWaU9DCWh89LojfZ537wTfunKau47EL2dhHKon", "xprv9zFnWC6h2cLgpmSA46vutJzBcfJ8yaJGg8cX1e5StJh45BBciYTRXSd25UEPVuesF9yog62tGAQtHjXajPPdbRCHuWS6T8XA2ECKADdw4Ef"},
+				{"xpub6ERApfZwUNrhLCkDtcHTcxd75RbzS1ed54G1LkBUHQVHQKqhMkhgbmJbZRkrgZw4koxb5JaHWkY4ALHY2grBGRjaDMzQLcgJvLJuZZvRcEL", "xprvA1RpRA33e1JQ7ifknakTFpgNXPmW2YvmhqLQYMmrj4xJXXWYpDPS3xz7iAxn8L39njGVyuoseXzU6rcxFLJ8HFsTjSyQbLYnMpCqE2VbFWc"},
+				{"xpub6FnCn6nSzZAw5Tw7cgR9bi15UV96gLZhjDstkXXxvCLsUXBGXPdSnLFbdpq8p9HmGsApME5hQTZ3emM2rnY5agb9rXpVGyy3bdW6EEgAtqt", "xprvA2nrNbFZABcdryreWet9Ea4LvTJcGsqrMzxHx98MMrotbir7yrKCEXw7nadnHM8Dq38EGfSh6dqA9QWTyefMLEcBYJUuekgW4BYPJcr9E7j"},
+			},
+		},
+		{
+			"4b381541583be4423346c643850da4b320e46a87ae3d2a4e6da11eba819cd4acba45d239319ac14f863b8d5ab5a0d0c64d2e8a1e7d1457df2e5a3c51c73235be",
+			[]uint32{HardenedKeyStart + 0},
+			[][2]string{
+				{"xpub661MyMwAqRbcEZVB4dScxMAdx6d4nFc9nvyvH3v4gJL378CSRZiYmhRoP7mBy6gSPSCYk6SzXPTf3ND1cZAceL7SfJ1Z3GC8vBgp2epUt13", "xprv9s21ZrQH143K25QhxbucbDDuQ4naNntJRi4KUfWT7xo4EKsHt2QJDu7KXp1A3u7Bi1j8ph3EGsZ9Xvz9dGuVrtHHs7pXeTzjuxBrCmmhgC6"},
+				{"xpub68NZiKmJWnxxS6aaHmn81bvJeTESw724CRDs6HbuccFQN9Ku14VQrADWgqbhhTHBaohPX4CjNLf9fq9MYo6oDaPPLPxSb7gwQN3ih19Zm4Y", "xprv9uPDJpEQgRQfDcW7BkF7eTya6RPxXeJCqCJGHuCJ4GiRVLzkTXBAJMu2qaMWPrS7AANYqdq6vcBcBUdJCVVFceUvJFjaPdGZ2y9WACViL4L"},
+			},
+		},
+		{
+			"3ddd5602285899a946114506157c7997e5444528f3003f6134712147db19b678",
+			[]uint32{HardenedKeyStart + 0, HardenedKeyStart + 1},
+			[][2]string{
+				{"xpub661MyMwAqRbcGczjuMoRm6dXaLDEhW1u34gKenbeYqAix21mdUKJyuyu5F1rzYGVxyL6tmgBUAEPrEz92mBXjByMRiJdba9wpnN37RLLAXa", "xprv9s21ZrQH143K48vGoLGRPxgo2JNkJ3J3fqkirQC2zVdk5Dgd5w14S7fRDyHH4dWNHUgkvsvNDCkvAwcSHNAQwhwgNMgZhLtQC63zxwhQmRv"},
+				{"xpub69AUMk3qDBi3uW1sXgjCmVjJ2G6WQoYSnNHyzkmdCHEhSZ4tBok37xfFEqHd2AddP56Tqp4o56AePAgCjYdvpW2PU2jbUPFKsav5ut6Ch1m", "xprv9vB7xEWwNp9kh1wQRfCCQMnZUEG21LpbR9NPCNN1dwhiZkjjeGRnaALmPXCX7SgjFTiCTT6bXes17boXtjq3xLpcDjzEuGLQBM5ohqkao9G"},
+				{"xpub6BJA1jSqiukeaesWfxe6sNK9CCGaujFFSJLomWHprUL9DePQ4JDkM5d88n49sMGJxrhpjazuXYWdMf17C9T5XnxkopaeS7jGk1GyyVziaMt", "xprv9xJocDuwtYCMNAo3Zw76WENQeAS6WGXQ55RCy7tDJ8oALr4FWkuVoHJeHVAcAqiZLE7Je3vZJHxspZdFHfnBEjHqU5hG1Jaj32dVoS6XLT1"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		seed, _ := hex.DecodeString(test.seed)
+		key, err := NewMasterKey(Mainet, seed)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for i, expect := range test.keys {
+			if i > 0 {
+				parent := key
+				if key, err = key.Child(test.path[i-1]); err != nil {
+					t.Fatal(err)
+				}
+
+				// public derivation matches private derivation for non-hardened children
+				if !key.IsHardened() {
+					neutered, err := parent.Neuter()
+					if err != nil {
+						t.Fatal(err)
+					}
+
+					child, err := neutered.Child(test.path[i-1])
+					if err != nil {
+						t.Fatal(err)
+					}
+
+					if child.String() != expect[0] {
+						t.Errorf("expect public child %s got %s", expect[0], child)
+					}
+				}
+			}
+
+			pub, err := key.Neuter()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if pub.String() != expect[0] {
+				t.Errorf("expect %s got %s", expect[0], pub)
+			}
+
+			if str, err := key.Encode(); err != nil || str != expect[1] {
+				t.Errorf("expect %s got %s %v", expect[1], str, err)
+			}
+
+			for _, str := range expect {
+				parsed, err := ParseExtendedKey(str)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if encoded, err := parsed.Encode(); err != nil || encoded != str {
+					t.Errorf("expect %s got %s %v", str, encoded, err)
+				}
+			}
+		}
+	}
+}
+
+func TestExtendedKeyDerivation(t *testing.T) {
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	master, err := NewMasterKey(Mainet, seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fp, err := master.Fingerprint()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if hex.EncodeToString(fp[:]) != "3442193e" {
+		t.Errorf("expect fingerprint 3442193e got %x", fp)
+	}
+
+	child, err := master.Child(HardenedKeyStart)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if child.ParentFingerprint != fp || child.Depth != 1 || child.ChildIndex != HardenedKeyStart {
+		t.Errorf("unexpected child %+v", child)
+	}
+
+	pk, err := child.PrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pubkey, err := child.PublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect, err := pk.PublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if pubkey.Hex() != expect.Hex() {
+		t.Errorf("expect %s got %s", expect, pubkey)
+	}
+
+	pub, err := master.Neuter()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := pub.Child(HardenedKeyStart); err != ErrHDDeriveHardenedFromPublic {
+		t.Errorf("expect ErrHDDeriveHardenedFromPublic got %v", err)
+	}
+
+	if _, err := pub.PrivateKey(); err != ErrHDNotPrivate {
+		t.Errorf("expect ErrHDNotPrivate got %v", err)
+	}
+
+	for _, n := range []int{MinSeedBytes - 1, MaxSeedBytes + 1} {
+		if _, err := NewMasterKey(Mainet, make([]byte, n)); err != ErrHDBadSeedLength {
+			t.Errorf("expect ErrHDBadSeedLength got %v", err)
+		}
+	}
+}
+
+// the invalid keys of BIP32 test vector 5 are built from a valid key
+func TestBadExtendedKey(t *testing.T) {
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	master, err := NewMasterKey(Mainet, seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pub, err := master.Neuter()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	prv, _ := master.Bytes()
+	pubBytes, _ := pub.Bytes()
+	xprv, _ := master.Encode()
+
+	modify := func(data []byte, f func(b []byte)) string {
+		b := append([]byte{}, data...)
+		f(b)
+		return Base58Encode(append(b, checksum(b)...))
+	}
+
+	tests := []struct {
+		str    string
+		expect error
+	}{
+		// pubkey version with prvkey data
+		{modify(prv, func(b []byte) { copy(b, pubBytes[:4]) }), ErrHDBadKey},
+		// prvkey version with pubkey data
+		{modify(pubBytes, func(b []byte) { copy(b, prv[:4]) }), ErrHDBadKey},
+		// invalid pubkey prefix 04
+		{modify(pubBytes, func(b []byte) { b[45] = 0x04 }), ErrHDBadKey},
+		// invalid prvkey prefix 04
+		{modify(prv, func(b []byte) { b[45] = 0x04 }), ErrHDBadKey},
+		// zero depth with non-zero parent fingerprint
+		{modify(prv, func(b []byte) { b[5] = 0x01 }), ErrHDBadFormat},
+		// zero depth with non-zero index
+		{modify(pubBytes, func(b []byte) { b[12] = 0x01 }), ErrHDBadFormat},
+		// unknown version
+		{modify(prv, func(b []byte) { copy(b, []byte{0xde, 0xad, 0xbe, 0xef}) }), ErrNetworkUnknown},
+		// private key 0 not in 1..n-1
+		{modify(prv, func(b []byte) { copy(b[46:], make([]byte, 32)) }), ErrHDBadKey},
+		// private key n not in 1..n-1
+		{modify(prv, func(b []byte) {
+			n, _ := hex.DecodeString("fffffffffffffffffffffffffffffffebaaedce6af48a03bbfd25e8cd0364141")
+			copy(b[46:], n)
+		}), ErrHDBadKey},
+		// invalid pubkey
+		{modify(pubBytes, func(b []byte) { copy(b[46:], make([]byte, 32)); b[46] = 0x07 }), ErrHDBadKey},
+		// invalid checksum
+		{xprv[:110] + "1", ErrBadChecksum},
+	}
+
+	for i, test := range tests {
+		if _, err := ParseExtendedKey(test.str); err != test.expect {
+			t.Errorf("%d: expect %v got %v", i, test.expect, err)
+		}
+	}
+}
+
+func TestExtendedKeyDestroy(t *testing.T) {
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	master, err := NewMasterKey(Mainet, seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path, _ := ParseDerivationPath("m/0'/1")
+	child, err := master.Derive(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Derive destroys the intermediate keys but not the key it starts from
+	if _, err := master.Child(0); err != nil {
+		t.Fatal(err)
+	}
+
+	pub, err := child.Neuter()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, chainCode := child.Key, child.ChainCode
+	child.Destroy()
+
+	if child.Key != nil || hex.EncodeToString(key) != hex.EncodeToString(make([]byte, 32)) ||
+		hex.EncodeToString(chainCode) != hex.EncodeToString(make([]byte, 32)) {
+		t.Errorf("expect wiped key got %x %x", key, chainCode)
+	}
+
+	if _, err := child.Child(0); err != ErrHDBadKey {
+		t.Errorf("expect ErrHDBadKey got %v", err)
+	}
+
+	// the neutered key owns its chain code
+	if pub.String() != "xpub6ASuArnXKPbfEwhqN6e3mwBcDTgzisQN1wXN9BJcM47sSikHjJf3UFHKkNAWbWMiGj7Wf5uMash7SyYq527Hqck2AxYysAA7xmALppuCkwQ" {
+		t.Errorf("expect the m/0'/1 xpub got %s", pub)
+	}
+}
+
+func TestExtendedKeyRedact(t *testing.T) {
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	master, err := NewMasterKey(Mainet, seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	xprv, err := master.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := "ExtendedKey(mainnet, depth 0, [REDACTED])"
+	if master.String() != expect {
+		t.Errorf("expect %s got %s", expect, master.String())
+	}
+
+	secret := hex.EncodeToString(master.Key)
+
+	for _, format := range []string{"%v", "%+v", "%#v", "%s", "%x", "%X", "%q", "%d"} {
+		for _, arg := range []interface{}{master, *master, struct{ Key ExtendedKey }{*master}, struct{ Key *ExtendedKey }{master}} {
+			out := fmt.Sprintf(format, arg)
+			if !strings.Contains(out, "[REDACTED]") || strings.Contains(out, xprv) || strings.Contains(strings.ToLower(out), secret) {
+				t.Errorf("%s: expect redacted got %s", format, out)
+			}
+		}
+	}
+
+	if out := fmt.Sprintf("%#v", master); !strings.HasPrefix(out, "bcrypto.ExtendedKey{") {
+		t.Errorf("expect GoString got %s", out)
+	}
+
+	// public keys still print as xpub
+	pub, err := master.Neuter()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	xpub := "xpub661MyMwAqRbcFtXgS5sYJABqqG9YLmC4Q1Rdap9gSE8NqtwybGhePY2gZ29ESFjqJoCu1Rupje8YtGqsefD265TMg7usUDFdp6W1EGMcet8"
+	if out := fmt.Sprintf("%v", pub); out != xpub {
+		t.Errorf("expect %s got %s", xpub, out)
+	}
+}
@@ -1,11 +1,14 @@
 package bcrypto
 
 import (
-	"errors"
+	"fmt"
 
 	"github.com/detailyang/go-bcrypto/secp256k1"
 )
 
+// Key is a raw secret without network, it is kept for compatibility and
+// delegates to PrivateKey which should be used instead. The key owns Data,
+// Destroy wipes it and the formatting methods redact it.
 type Key struct {
 	Data       []byte
 	Compressed bool
@@ -18,13 +21,28 @@ func NewKey(k []byte, compressed bool) *Key {
 	}
 }
 
+// NewKeyFromPrivateKey returns the raw key of the private key
+func NewKeyFromPrivateKey(pk *PrivateKey) *Key {
+	return NewKey(pk.secretBytes(), pk.Compressed)
+}
+
+// PrivateKey returns the private key of the raw key on the network
+func (k *Key) PrivateKey(network Network) (*PrivateKey, error) {
+	if len(k.Data) != 32 {
+		return nil, ErrPrivateBadSecret
+	}
+
+	return newPrivateKey(network, k.Data, k.Compressed)
+}
+
 func (k *Key) GetPubkey() (PublicKey, error) {
-	pubkey, ok := secp256k1.CreatePubkeyFromBytes(k.Data, k.Compressed)
-	if !ok {
-		return nil, errors.New("create pubkey failed")
+	pk, err := k.PrivateKey(Mainet)
+	if err != nil {
+		return nil, err
 	}
+	defer pk.Destroy()
 
-	return NewPublicKey(pubkey), nil
+	return pk.PublicKey()
 }
 
 //  ECDSA-Sig-Value ::= SEQUENCE {
@@ -32,10 +50,49 @@ func (k *Key) GetPubkey() (PublicKey, error) {
 // s INTEGER }
 // See https://tools.ietf.org/html/rfc3278#section-8.2
 func (k *Key) Signature(msg []byte, testCase uint32) ([]byte, bool) {
-	sig, ok := secp256k1.Signature(msg, k.Data, testCase)
-	if !ok {
+	pk, err := k.PrivateKey(Mainet)
+	if err != nil {
+		return nil, false
+	}
+	defer pk.Destroy()
+
+	sig, err := pk.sign(msg, testCase)
+	if err != nil {
 		return nil, false
 	}
 
 	return sig, true
 }
+
+// SignRecoverable returns the 65-byte [R || S || V] signature of the 32-byte msg
+func (k *Key) SignRecoverable(msg []byte) ([]byte, error) {
+	pk, err := k.PrivateKey(Mainet)
+	if err != nil {
+		return nil, err
+	}
+	defer pk.Destroy()
+
+	return pk.SignRecoverable(msg)
+}
+
+// Destroy wipes Data and drops it, the key can't sign afterwards
+func (k *Key) Destroy() {
+	secp256k1.Zeroize(k.Data)
+	k.Data = nil
+}
+
+// String redacts the secret, it has a value receiver so that a Key printed by
+// value is redacted too, copying a Key only copies the slice header
+func (k Key) String() string {
+	return fmt.Sprintf("Key(%s, [REDACTED])", compressionString(k.Compressed))
+}
+
+// GoString redacts the secret for %#v
+func (k Key) GoString() string {
+	return fmt.Sprintf("bcrypto.Key{Data: [REDACTED], Compressed: %t}", k.Compressed)
+}
+
+// Format implements fmt.Formatter so that every verb, %x and %d included, is redacted
+func (k Key) Format(f fmt.State, verb rune) {
+	formatRedacted(f, verb, k.String(), k.GoString())
+}
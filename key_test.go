@@ -1,6 +1,10 @@
 package bcrypto
 
 import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"strings"
 	"testing"
 )
 
@@ -52,3 +56,73 @@ func TestKey(t *testing.T) {
 		t.Error("expect 03363d90d447b00c9c99ceac05b6262ee053441c7e55552ffe526bad8f83ff4640")
 	}
 }
+
+func TestKeyPrivateKey(t *testing.T) {
+	data := make([]byte, 32)
+	data[31] = 1
+
+	pk, err := NewKey(data, true).PrivateKey(Testnet)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if pk.Base58() != "cMahea7zqjxrtgAbB7LSGbcQUr1uX1ojuat9jZodMN87JcbXMTcA" {
+		t.Errorf("expect %s got %s", "cMahea7zqjxrtgAbB7LSGbcQUr1uX1ojuat9jZodMN87JcbXMTcA", pk.Base58())
+	}
+
+	key := NewKeyFromPrivateKey(pk)
+	if !bytes.Equal(key.Data, data) || !key.Compressed {
+		t.Errorf("expect %x got %x", data, key.Data)
+	}
+
+	_, err = NewKey(make([]byte, 32), true).GetPubkey()
+	if err != ErrPrivateBadSecret {
+		t.Errorf("expect ErrPrivateBadSecret got %v", err)
+	}
+}
+
+func TestKeyRecover(t *testing.T) {
+	data := make([]byte, 32)
+	data[31] = 1
+	msg := make([]byte, 32)
+	copy(msg, "hi there")
+
+	for _, compressed := range []bool{false, true} {
+		key := NewKey(data, compressed)
+		sig, err := key.SignRecoverable(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		pubkey, err := RecoverPublicKey(msg, sig, compressed)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expect, _ := key.GetPubkey()
+		if !bytes.Equal(pubkey, expect) {
+			t.Errorf("expect %s got %s", expect, pubkey)
+		}
+	}
+}
+
+func TestKeyDestroy(t *testing.T) {
+	data, _ := hex.DecodeString("0000000000000000000000000000000000000000000000000000000000000001")
+	key := NewKey(data, true)
+
+	for _, format := range []string{"%v", "%#v", "%x", "%s"} {
+		out := fmt.Sprintf(format, key)
+		if !strings.Contains(out, "[REDACTED]") || strings.Contains(out, hex.EncodeToString(data)) {
+			t.Errorf("%s: expect redacted got %s", format, out)
+		}
+	}
+
+	key.Destroy()
+	if key.Data != nil || !bytes.Equal(data, make([]byte, 32)) {
+		t.Errorf("expect wiped data got %x", data)
+	}
+
+	if _, err := key.GetPubkey(); err != ErrPrivateBadSecret {
+		t.Errorf("expect ErrPrivateBadSecret got %v", err)
+	}
+}
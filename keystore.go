@@ -0,0 +1,648 @@
+package bcrypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/detailyang/go-bcrypto/secp256k1"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/scrypt"
+)
+
+// KeystoreVersion is the version of the keystore file format
+const KeystoreVersion = 1
+
+const (
+	// keystoreKeySize is the length of the encryption key derived from a passphrase
+	keystoreKeySize = 32
+	// keystoreSaltSize is the length of the random KDF salt of each key
+	keystoreSaltSize = 32
+	// gcmNonceSize is the standard nonce length of AES-GCM
+	gcmNonceSize = 12
+
+	// keystoreMaxKDFMemory bounds the bytes a KDF may allocate, a key file is
+	// untrusted input and must not exhaust memory before the passphrase is checked
+	keystoreMaxKDFMemory = 1 << 30
+	// keystoreMaxKDFPasses bounds scrypt p and argon2 time
+	keystoreMaxKDFPasses = 16
+)
+
+// KDF is the passphrase key derivation function of a keystore key
+type KDF string
+
+const (
+	KDFScrypt   KDF = "scrypt"
+	KDFArgon2id KDF = "argon2id"
+)
+
+// Cipher is the authenticated encryption of a keystore key
+type Cipher string
+
+const (
+	CipherAESGCM            Cipher = "aes-256-gcm"
+	CipherXChaCha20Poly1305 Cipher = "xchacha20-poly1305"
+)
+
+var (
+	// ErrKeystoreBadVersion represents the keystore file has an unsupported version
+	ErrKeystoreBadVersion = errors.New("keystore: bad version")
+	// ErrKeystoreBadFormat represents the keystore file or one of its keys can not be decoded
+	ErrKeystoreBadFormat = errors.New("keystore: bad format")
+	// ErrKeystoreBadKDF represents the KDF is unknown or its parameters are invalid
+	ErrKeystoreBadKDF = errors.New("keystore: bad kdf")
+	// ErrKeystoreBadCipher represents the cipher is unknown
+	ErrKeystoreBadCipher = errors.New("keystore: bad cipher")
+	// ErrKeystoreBadPassphrase represents the key can not be decrypted with the passphrase
+	ErrKeystoreBadPassphrase = errors.New("keystore: bad passphrase")
+	// ErrKeystoreDuplicateLabel represents a key with the same label is already stored
+	ErrKeystoreDuplicateLabel = errors.New("keystore: duplicate label")
+	// ErrKeystoreNotFound represents no key is stored with the label
+	ErrKeystoreNotFound = errors.New("keystore: key not found")
+	// ErrKeystoreLocked represents the key is not unlocked
+	ErrKeystoreLocked = errors.New("keystore: key locked")
+)
+
+// KeystoreParams selects how Import encrypts new keys, Argon2Memory is in KiB
+type KeystoreParams struct {
+	KDF    KDF
+	Cipher Cipher
+
+	ScryptN int
+	ScryptR int
+	ScryptP int
+
+	Argon2Time    uint32
+	Argon2Memory  uint32
+	Argon2Threads uint8
+}
+
+var (
+	// DefaultKeystoreParams are the parameters for keys kept on disk
+	DefaultKeystoreParams = KeystoreParams{
+		KDF:           KDFScrypt,
+		Cipher:        CipherAESGCM,
+		ScryptN:       1 << 18,
+		ScryptR:       8,
+		ScryptP:       1,
+		Argon2Time:    3,
+		Argon2Memory:  64 * 1024,
+		Argon2Threads: 4,
+	}
+
+	// LightKeystoreParams trade brute force resistance for speed, e.g. in tests
+	LightKeystoreParams = KeystoreParams{
+		KDF:           KDFScrypt,
+		Cipher:        CipherAESGCM,
+		ScryptN:       1 << 12,
+		ScryptR:       8,
+		ScryptP:       1,
+		Argon2Time:    1,
+		Argon2Memory:  4 * 1024,
+		Argon2Threads: 1,
+	}
+)
+
+// KeystoreEntry describes a stored key without decrypting it
+type KeystoreEntry struct {
+	Label     string
+	Network   Network
+	PublicKey PublicKey
+	Unlocked  bool
+}
+
+// Keystore is a collection of passphrase-encrypted private keys with labels and network tags,
+// keys are decrypted into memory by Unlock and zeroed by Lock
+type Keystore struct {
+	mu       sync.Mutex
+	params   KeystoreParams
+	keys     []*keystoreKey
+	unlocked map[string]*PrivateKey
+}
+
+type keystoreFile struct {
+	Version int            `json:"version"`
+	Keys    []*keystoreKey `json:"keys"`
+}
+
+type keystoreKey struct {
+	Label     string         `json:"label"`
+	Network   string         `json:"network"`
+	PublicKey string         `json:"pubkey"`
+	Crypto    keystoreCrypto `json:"crypto"`
+
+	network Network
+	pubkey  PublicKey
+}
+
+type keystoreCrypto struct {
+	KDF        KDF               `json:"kdf"`
+	KDFParams  keystoreKDFParams `json:"kdfparams"`
+	Cipher     Cipher            `json:"cipher"`
+	Nonce      string            `json:"nonce"`
+	Ciphertext string            `json:"ciphertext"`
+}
+
+type keystoreKDFParams struct {
+	Salt    string `json:"salt"`
+	N       int    `json:"n,omitempty"`
+	R       int    `json:"r,omitempty"`
+	P       int    `json:"p,omitempty"`
+	Time    uint32 `json:"time,omitempty"`
+	Memory  uint32 `json:"memory,omitempty"`
+	Threads uint8  `json:"threads,omitempty"`
+}
+
+// NewKeystore returns an empty keystore encrypting new keys with the params
+func NewKeystore(params KeystoreParams) *Keystore {
+	return &Keystore{
+		params:   params,
+		unlocked: make(map[string]*PrivateKey),
+	}
+}
+
+// Params returns the parameters Import encrypts new keys with
+func (ks *Keystore) Params() KeystoreParams {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	return ks.params
+}
+
+// SetParams selects how Import encrypts new keys, stored keys are left as is
+func (ks *Keystore) SetParams(params KeystoreParams) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	ks.params = params
+}
+
+// ParseKeystore decodes the JSON keystore file, new keys are encrypted with DefaultKeystoreParams
+func ParseKeystore(data []byte) (*Keystore, error) {
+	var file keystoreFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, ErrKeystoreBadFormat
+	}
+
+	if file.Version != KeystoreVersion {
+		return nil, ErrKeystoreBadVersion
+	}
+
+	ks := NewKeystore(DefaultKeystoreParams)
+	for _, key := range file.Keys {
+		if key == nil {
+			return nil, ErrKeystoreBadFormat
+		}
+
+		network, err := NetworkByName(key.Network)
+		if err != nil {
+			return nil, err
+		}
+
+		pubkey, err := hex.DecodeString(key.PublicKey)
+		if err != nil {
+			return nil, ErrKeystoreBadFormat
+		}
+
+		if _, err := NewPublicKey(pubkey).ToECDSA(); err != nil {
+			return nil, ErrKeystoreBadFormat
+		}
+
+		if err := key.Crypto.validate(); err != nil {
+			return nil, err
+		}
+
+		if ks.find(key.Label) != nil {
+			return nil, ErrKeystoreDuplicateLabel
+		}
+
+		key.network = network
+		key.pubkey = NewPublicKey(pubkey)
+		ks.keys = append(ks.keys, key)
+	}
+
+	return ks, nil
+}
+
+// OpenKeystore reads the keystore file at path
+func OpenKeystore(path string) (*Keystore, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseKeystore(data)
+}
+
+// Marshal encodes the keystore file, unlocked keys are written encrypted
+func (ks *Keystore) Marshal() ([]byte, error) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	file := keystoreFile{
+		Version: KeystoreVersion,
+		Keys:    ks.keys,
+	}
+	if file.Keys == nil {
+		file.Keys = []*keystoreKey{}
+	}
+
+	return json.MarshalIndent(file, "", "  ")
+}
+
+// Save writes the keystore file to path readable by the owner only,
+// through a temporary file so a crash never leaves a truncated keystore
+func (ks *Keystore) Save(path string) error {
+	data, err := ks.Marshal()
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := tmp.Chmod(0600); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// Import encrypts the private key with the passphrase and stores it under the label,
+// tagged with the network of the key
+func (ks *Keystore) Import(pk *PrivateKey, label, passphrase string) error {
+	pubkey, err := pk.PublicKey()
+	if err != nil {
+		return err
+	}
+
+	params, err := pk.Network.Params()
+	if err != nil {
+		return err
+	}
+
+	key := &keystoreKey{
+		Label:     label,
+		Network:   params.Name,
+		PublicKey: pubkey.Hex(),
+		network:   pk.Network,
+		pubkey:    pubkey,
+	}
+
+	// the label is checked before the KDF runs, and again when the key is stored
+	ks.mu.Lock()
+	p := ks.params
+	duplicate := ks.find(label) != nil
+	ks.mu.Unlock()
+
+	if duplicate {
+		return ErrKeystoreDuplicateLabel
+	}
+
+	salt := make([]byte, keystoreSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+
+	key.Crypto = keystoreCrypto{
+		KDF:    p.KDF,
+		Cipher: p.Cipher,
+		KDFParams: keystoreKDFParams{
+			Salt: hex.EncodeToString(salt),
+		},
+	}
+
+	switch p.KDF {
+	case KDFScrypt:
+		key.Crypto.KDFParams.N = p.ScryptN
+		key.Crypto.KDFParams.R = p.ScryptR
+		key.Crypto.KDFParams.P = p.ScryptP
+	case KDFArgon2id:
+		key.Crypto.KDFParams.Time = p.Argon2Time
+		key.Crypto.KDFParams.Memory = p.Argon2Memory
+		key.Crypto.KDFParams.Threads = p.Argon2Threads
+	default:
+		return ErrKeystoreBadKDF
+	}
+
+	aead, err := key.aead(passphrase)
+	if err != nil {
+		return err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+
+	secret := pk.secretBytes()
+	defer secp256k1.Zeroize(secret)
+
+	key.Crypto.Nonce = hex.EncodeToString(nonce)
+	key.Crypto.Ciphertext = hex.EncodeToString(aead.Seal(nil, nonce, secret, key.additionalData()))
+
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	if ks.find(label) != nil {
+		return ErrKeystoreDuplicateLabel
+	}
+
+	ks.keys = append(ks.keys, key)
+	return nil
+}
+
+// Export decrypts the key stored under the label and returns a copy the caller owns,
+// the keystore is left as is
+func (ks *Keystore) Export(label, passphrase string) (*PrivateKey, error) {
+	ks.mu.Lock()
+	key := ks.find(label)
+	ks.mu.Unlock()
+
+	if key == nil {
+		return nil, ErrKeystoreNotFound
+	}
+
+	return key.decrypt(passphrase)
+}
+
+// Unlock decrypts the key stored under the label and keeps it in memory until Lock
+func (ks *Keystore) Unlock(label, passphrase string) error {
+	ks.mu.Lock()
+	key := ks.find(label)
+	ks.mu.Unlock()
+
+	if key == nil {
+		return ErrKeystoreNotFound
+	}
+
+	// the passphrase is checked even if the key is already unlocked
+	pk, err := key.decrypt(passphrase)
+	if err != nil {
+		return err
+	}
+
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	if _, ok := ks.unlocked[label]; ok {
+		pk.Destroy()
+		return nil
+	}
+
+	ks.unlocked[label] = pk
+	return nil
+}
+
+// Lock zeroes the unlocked key stored under the label,
+// private keys returned by PrivateKey are zeroed as well
+func (ks *Keystore) Lock(label string) error {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	if ks.find(label) == nil {
+		return ErrKeystoreNotFound
+	}
+
+	if pk, ok := ks.unlocked[label]; ok {
+		pk.Destroy()
+		delete(ks.unlocked, label)
+	}
+
+	return nil
+}
+
+// LockAll zeroes every unlocked key
+func (ks *Keystore) LockAll() {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	for label, pk := range ks.unlocked {
+		pk.Destroy()
+		delete(ks.unlocked, label)
+	}
+}
+
+// PrivateKey returns the unlocked key stored under the label,
+// it is shared with the keystore and zeroed by Lock
+func (ks *Keystore) PrivateKey(label string) (*PrivateKey, error) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	if ks.find(label) == nil {
+		return nil, ErrKeystoreNotFound
+	}
+
+	pk, ok := ks.unlocked[label]
+	if !ok {
+		return nil, ErrKeystoreLocked
+	}
+
+	return pk, nil
+}
+
+// Delete removes the key stored under the label, zeroing it if unlocked
+func (ks *Keystore) Delete(label string) error {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	for i, key := range ks.keys {
+		if key.Label == label {
+			if pk, ok := ks.unlocked[label]; ok {
+				pk.Destroy()
+				delete(ks.unlocked, label)
+			}
+
+			ks.keys = append(ks.keys[:i], ks.keys[i+1:]...)
+			return nil
+		}
+	}
+
+	return ErrKeystoreNotFound
+}
+
+// List describes the stored keys in import order
+func (ks *Keystore) List() []KeystoreEntry {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	entries := make([]KeystoreEntry, len(ks.keys))
+	for i, key := range ks.keys {
+		_, unlocked := ks.unlocked[key.Label]
+		entries[i] = KeystoreEntry{
+			Label:     key.Label,
+			Network:   key.network,
+			PublicKey: key.pubkey,
+			Unlocked:  unlocked,
+		}
+	}
+
+	return entries
+}
+
+func (ks *Keystore) find(label string) *keystoreKey {
+	for _, key := range ks.keys {
+		if key.Label == label {
+			return key
+		}
+	}
+
+	return nil
+}
+
+// additionalData binds the ciphertext to the network tag and public key of the entry
+func (key *keystoreKey) additionalData() []byte {
+	return append([]byte(key.Network+"\x00"), key.pubkey...)
+}
+
+func (key *keystoreKey) aead(passphrase string) (cipher.AEAD, error) {
+	salt, err := hex.DecodeString(key.Crypto.KDFParams.Salt)
+	if err != nil || len(salt) == 0 {
+		return nil, ErrKeystoreBadFormat
+	}
+
+	if err := key.Crypto.checkKDF(); err != nil {
+		return nil, err
+	}
+
+	params := key.Crypto.KDFParams
+
+	var derived []byte
+	switch key.Crypto.KDF {
+	case KDFScrypt:
+		if derived, err = scrypt.Key([]byte(passphrase), salt, params.N, params.R, params.P, keystoreKeySize); err != nil {
+			return nil, ErrKeystoreBadKDF
+		}
+	case KDFArgon2id:
+		derived = argon2.IDKey([]byte(passphrase), salt, params.Time, params.Memory, params.Threads, keystoreKeySize)
+	}
+	defer secp256k1.Zeroize(derived)
+
+	switch key.Crypto.Cipher {
+	case CipherAESGCM:
+		block, err := aes.NewCipher(derived)
+		if err != nil {
+			return nil, err
+		}
+		return cipher.NewGCM(block)
+	case CipherXChaCha20Poly1305:
+		return chacha20poly1305.NewX(derived)
+	}
+
+	return nil, ErrKeystoreBadCipher
+}
+
+// validate checks the KDF, the cipher and the nonce of a parsed key, so a bad
+// file is rejected by ParseKeystore rather than by the first decrypt
+func (c *keystoreCrypto) validate() error {
+	if err := c.checkKDF(); err != nil {
+		return err
+	}
+
+	var nonceSize int
+	switch c.Cipher {
+	case CipherAESGCM:
+		nonceSize = gcmNonceSize
+	case CipherXChaCha20Poly1305:
+		nonceSize = chacha20poly1305.NonceSizeX
+	default:
+		return ErrKeystoreBadCipher
+	}
+
+	nonce, err := hex.DecodeString(c.Nonce)
+	if err != nil || len(nonce) != nonceSize {
+		return ErrKeystoreBadFormat
+	}
+
+	return nil
+}
+
+// checkKDF checks the KDF is known and its parameters are within the bounds
+func (c *keystoreCrypto) checkKDF() error {
+	params := c.KDFParams
+
+	switch c.KDF {
+	case KDFScrypt:
+		// scrypt allocates 128 * N * r bytes
+		if params.N <= 1 || params.N&(params.N-1) != 0 || params.R <= 0 || params.P <= 0 ||
+			params.P > keystoreMaxKDFPasses || params.N > keystoreMaxKDFMemory/128/params.R {
+			return ErrKeystoreBadKDF
+		}
+	case KDFArgon2id:
+		// argon2 memory is in KiB
+		if params.Time == 0 || params.Time > keystoreMaxKDFPasses || params.Threads == 0 ||
+			params.Memory == 0 || params.Memory > keystoreMaxKDFMemory/1024 {
+			return ErrKeystoreBadKDF
+		}
+	default:
+		return ErrKeystoreBadKDF
+	}
+
+	return nil
+}
+
+func (key *keystoreKey) decrypt(passphrase string) (*PrivateKey, error) {
+	nonce, err := hex.DecodeString(key.Crypto.Nonce)
+	if err != nil {
+		return nil, ErrKeystoreBadFormat
+	}
+
+	ciphertext, err := hex.DecodeString(key.Crypto.Ciphertext)
+	if err != nil {
+		return nil, ErrKeystoreBadFormat
+	}
+
+	aead, err := key.aead(passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(nonce) != aead.NonceSize() {
+		return nil, ErrKeystoreBadFormat
+	}
+
+	secret, err := aead.Open(nil, nonce, ciphertext, key.additionalData())
+	if err != nil {
+		return nil, ErrKeystoreBadPassphrase
+	}
+	defer secp256k1.Zeroize(secret)
+
+	pk, err := newPrivateKey(key.network, secret, key.pubkey.IsCompressed())
+	if err != nil {
+		return nil, ErrKeystoreBadFormat
+	}
+
+	// the ciphertext is authenticated, a mismatch means the file was crafted
+	pubkey, err := pk.PublicKey()
+	if err != nil || pubkey.Hex() != key.pubkey.Hex() {
+		pk.Destroy()
+		return nil, ErrKeystoreBadFormat
+	}
+
+	return pk, nil
+}
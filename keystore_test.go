@@ -0,0 +1,262 @@
+package bcrypto
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/detailyang/go-bprimitives"
+)
+
+func TestKeystore(t *testing.T) {
+	mainnet, err := NewPrivateKeyFromWIF("L44B5gGEpqEDRS9vVPz7QT35jcBG2r3CZwSwQ4fCewXAhAhqGVpP")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	uncompressed, err := NewPrivateKeyFromWIF("5KN7MzqK5wt2TP1fQCYyHBtDrXdJuXbUzm4A9rKAteGu3Qi5CVR")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testnet, err := NewPrivateKeyFromHash(Testnet, *mainnet.secret, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ks := NewKeystore(LightKeystoreParams)
+	if err := ks.Import(mainnet, "hot", "correct horse"); err != nil {
+		t.Fatal(err)
+	}
+
+	params := LightKeystoreParams
+	params.KDF = KDFArgon2id
+	params.Cipher = CipherXChaCha20Poly1305
+	ks.SetParams(params)
+	if err := ks.Import(uncompressed, "cold", "battery staple"); err != nil {
+		t.Fatal(err)
+	}
+
+	params.Cipher = CipherAESGCM
+	ks.SetParams(params)
+	if err := ks.Import(testnet, "test", "staple"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ks.Import(testnet, "hot", "staple"); err != ErrKeystoreDuplicateLabel {
+		t.Errorf("expect ErrKeystoreDuplicateLabel got %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "keystore.json")
+	if err := ks.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("expect mode 0600 got %v", info.Mode().Perm())
+	}
+
+	data, _ := os.ReadFile(path)
+	if bytes.Contains(data, []byte(mainnet.Hex())) || !bytes.Contains(data, []byte(`"kdf": "argon2id"`)) {
+		t.Errorf("unexpected keystore file %s", data)
+	}
+
+	loaded, err := OpenKeystore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		label      string
+		passphrase string
+		key        *PrivateKey
+	}{
+		{"hot", "correct horse", mainnet},
+		{"cold", "battery staple", uncompressed},
+		{"test", "staple", testnet},
+	}
+
+	entries := loaded.List()
+	if len(entries) != len(tests) {
+		t.Fatalf("expect %d entries got %d", len(tests), len(entries))
+	}
+
+	for i, test := range tests {
+		pubkey, _ := test.key.PublicKey()
+		entry := entries[i]
+		if entry.Label != test.label || entry.Network != test.key.Network || entry.PublicKey.Hex() != pubkey.Hex() || entry.Unlocked {
+			t.Errorf("unexpected entry %+v", entry)
+		}
+
+		if _, err := loaded.PrivateKey(test.label); err != ErrKeystoreLocked {
+			t.Errorf("expect ErrKeystoreLocked got %v", err)
+		}
+
+		if err := loaded.Unlock(test.label, "wrong"); err != ErrKeystoreBadPassphrase {
+			t.Errorf("expect ErrKeystoreBadPassphrase got %v", err)
+		}
+
+		exported, err := loaded.Export(test.label, test.passphrase)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if exported.Hex() != test.key.Hex() {
+			t.Errorf("expect %s got %s", test.key.Hex(), exported.Hex())
+		}
+
+		if err := loaded.Unlock(test.label, test.passphrase); err != nil {
+			t.Fatal(err)
+		}
+
+		pk, err := loaded.PrivateKey(test.label)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if pk.Hex() != test.key.Hex() {
+			t.Errorf("expect %s got %s", test.key.Hex(), pk.Hex())
+		}
+
+		if !loaded.List()[i].Unlocked {
+			t.Errorf("expect %s unlocked", test.label)
+		}
+
+		if err := loaded.Lock(test.label); err != nil {
+			t.Fatal(err)
+		}
+
+		if pk.secret != nil {
+			t.Errorf("expect %s zeroed after Lock", test.label)
+		}
+
+		if exported.secret == nil || *exported.secret == (Hash{}) {
+			t.Errorf("expect the exported %s to survive Lock", test.label)
+		}
+	}
+
+	if err := loaded.Unlock("missing", ""); err != ErrKeystoreNotFound {
+		t.Errorf("expect ErrKeystoreNotFound got %v", err)
+	}
+
+	if err := loaded.Unlock("hot", "correct horse"); err != nil {
+		t.Fatal(err)
+	}
+
+	// an unlocked key still checks the passphrase and keeps the held key
+	if err := loaded.Unlock("hot", "wrong"); err != ErrKeystoreBadPassphrase {
+		t.Errorf("expect ErrKeystoreBadPassphrase got %v", err)
+	}
+
+	pk, _ := loaded.PrivateKey("hot")
+	if err := loaded.Unlock("hot", "correct horse"); err != nil {
+		t.Fatal(err)
+	}
+
+	if held, _ := loaded.PrivateKey("hot"); held != pk {
+		t.Error("expect the unlocked key kept")
+	}
+
+	if err := loaded.Delete("hot"); err != nil {
+		t.Fatal(err)
+	}
+
+	if pk.secret != nil || len(loaded.List()) != 2 {
+		t.Error("expect the deleted key to be zeroed and removed")
+	}
+}
+
+func TestKeystoreBadFile(t *testing.T) {
+	mainnet, err := NewPrivateKeyFromWIF("L44B5gGEpqEDRS9vVPz7QT35jcBG2r3CZwSwQ4fCewXAhAhqGVpP")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ks := NewKeystore(LightKeystoreParams)
+	if err := ks.Import(mainnet, "hot", "correct horse"); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := ks.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// moving the ciphertext to another network fails authentication
+	retagged, err := ParseKeystore(bytes.Replace(data, []byte(`"mainnet"`), []byte(`"testnet"`), 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := retagged.Export("hot", "correct horse"); err != ErrKeystoreBadPassphrase {
+		t.Errorf("expect ErrKeystoreBadPassphrase got %v", err)
+	}
+
+	tests := []struct {
+		data   string
+		expect error
+	}{
+		{`{"version": 2, "keys": []}`, ErrKeystoreBadVersion},
+		{`{"version": 1, "keys": [null]}`, ErrKeystoreBadFormat},
+		{`not json`, ErrKeystoreBadFormat},
+		{strings.Replace(string(data), `"mainnet"`, `"dogecoin"`, 1), ErrNetworkUnknown},
+		{strings.Replace(string(data), `"pubkey": "0`, `"pubkey": "9`, 1), ErrKeystoreBadFormat},
+		{strings.Replace(string(data), `"n": 4096`, `"n": 1073741824`, 1), ErrKeystoreBadKDF},
+		{strings.Replace(string(data), `"n": 4096`, `"n": 4095`, 1), ErrKeystoreBadKDF},
+		{strings.Replace(string(data), `"p": 1`, `"p": 1000`, 1), ErrKeystoreBadKDF},
+		{strings.Replace(string(data), `"scrypt"`, `"pbkdf2"`, 1), ErrKeystoreBadKDF},
+		{strings.Replace(string(data), `"aes-256-gcm"`, `"rot13"`, 1), ErrKeystoreBadCipher},
+		{strings.Replace(string(data), `"nonce": "`, `"nonce": "00`, 1), ErrKeystoreBadFormat},
+		{strings.Replace(string(data), `"aes-256-gcm"`, `"xchacha20-poly1305"`, 1), ErrKeystoreBadFormat},
+	}
+
+	for _, test := range tests {
+		if _, err := ParseKeystore([]byte(test.data)); err != test.expect {
+			t.Errorf("expect %v got %v", test.expect, err)
+		}
+	}
+
+	params := LightKeystoreParams
+	params.KDF = "pbkdf2"
+	ks.SetParams(params)
+
+	// the label is checked before the KDF runs
+	if err := ks.Import(mainnet, "hot", "correct horse"); err != ErrKeystoreDuplicateLabel {
+		t.Errorf("expect ErrKeystoreDuplicateLabel got %v", err)
+	}
+
+	if err := ks.Import(mainnet, "other", "correct horse"); err != ErrKeystoreBadKDF {
+		t.Errorf("expect ErrKeystoreBadKDF got %v", err)
+	}
+
+	params = LightKeystoreParams
+	params.KDF = KDFArgon2id
+	params.Argon2Memory = 1<<32 - 1
+	ks.SetParams(params)
+	if err := ks.Import(mainnet, "other", "correct horse"); err != ErrKeystoreBadKDF {
+		t.Errorf("expect ErrKeystoreBadKDF got %v", err)
+	}
+
+	params = LightKeystoreParams
+	params.Cipher = "rot13"
+	ks.SetParams(params)
+	if err := ks.Import(mainnet, "other", "correct horse"); err != ErrKeystoreBadCipher {
+		t.Errorf("expect ErrKeystoreBadCipher got %v", err)
+	}
+
+	empty, err := NewKeystore(LightKeystoreParams).Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ParseKeystore(empty); err != nil {
+		t.Errorf("expect an empty keystore to round trip got %v", err)
+	}
+}
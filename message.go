@@ -0,0 +1,162 @@
+package bcrypto
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"io"
+
+	. "github.com/detailyang/go-bprimitives"
+)
+
+const (
+	messageMagic = "Bitcoin Signed Message:\n"
+
+	// header byte of compact signatures: 27 + recid, +4 for compressed keys
+	compactHeaderBase       = 27
+	compactHeaderCompressed = 4
+)
+
+var (
+	// ErrMessageBadSignature represents the signature is not a 65-byte compact signature
+	ErrMessageBadSignature = errors.New("bad message signature")
+	// ErrMessageBadAddress represents the address can not verify signed messages
+	ErrMessageBadAddress = errors.New("bad message address")
+)
+
+// MessageHash returns the double SHA256 of the magic prefixed message
+func MessageHash(text string) []byte {
+	var buf bytes.Buffer
+	putVarBytes(&buf, []byte(messageMagic))
+	putVarBytes(&buf, []byte(text))
+	return DHash256(buf.Bytes()).TakeBytes(0, 32)
+}
+
+// SignMessage signs the message like bitcoin core signmessage,
+// it returns the base64 of the 65-byte compact signature
+func SignMessage(pk *PrivateKey, text string) (string, error) {
+	sig, err := pk.SignRecoverable(MessageHash(text))
+	if err != nil {
+		return "", err
+	}
+
+	header := byte(compactHeaderBase) + sig[64]
+	if pk.Compressed {
+		header += compactHeaderCompressed
+	}
+
+	compact := append([]byte{header}, sig[:64]...)
+	return base64.StdEncoding.EncodeToString(compact), nil
+}
+
+// VerifyMessage verifies the signature like bitcoin core verifymessage,
+// the key recovered from the signature must hash to the P2PKH address
+func VerifyMessage(address *Address, signature, text string) (bool, error) {
+	if address.Kind != AddressP2PKH {
+		return false, ErrMessageBadAddress
+	}
+
+	compact, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return false, err
+	}
+
+	if len(compact) != 65 {
+		return false, ErrMessageBadSignature
+	}
+
+	header := compact[0]
+	if header < compactHeaderBase || header >= compactHeaderBase+2*compactHeaderCompressed {
+		return false, ErrMessageBadSignature
+	}
+
+	compressed := header >= compactHeaderBase+compactHeaderCompressed
+	recid := (header - compactHeaderBase) & 3
+	sig := append(append([]byte{}, compact[1:]...), recid)
+
+	pubkey, err := RecoverPublicKey(MessageHash(text), sig, compressed)
+	if err != nil {
+		return false, nil
+	}
+
+	return bytes.Equal(pubkey.ID(), address.Hash), nil
+}
+
+// putVarBytes writes the bytes prefixed with their CompactSize length
+func putVarBytes(buf *bytes.Buffer, data []byte) {
+	putVarInt(buf, uint64(len(data)))
+	buf.Write(data)
+}
+
+// putVarInt writes the CompactSize unsigned integer
+// see https://en.bitcoin.it/wiki/Protocol_documentation#Variable_length_integer
+func putVarInt(buf *bytes.Buffer, n uint64) {
+	var b [9]byte
+
+	switch {
+	case n < 0xfd:
+		buf.WriteByte(byte(n))
+	case n <= 0xffff:
+		b[0] = 0xfd
+		binary.LittleEndian.PutUint16(b[1:], uint16(n))
+		buf.Write(b[:3])
+	case n <= 0xffffffff:
+		b[0] = 0xfe
+		binary.LittleEndian.PutUint32(b[1:], uint32(n))
+		buf.Write(b[:5])
+	default:
+		b[0] = 0xff
+		binary.LittleEndian.PutUint64(b[1:], n)
+		buf.Write(b[:9])
+	}
+}
+
+// readVarBytes reads the bytes prefixed with their CompactSize length
+func readVarBytes(r *bytes.Reader) ([]byte, error) {
+	n, err := readVarInt(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if n > uint64(r.Len()) {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	data := make([]byte, n)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// readVarInt reads the CompactSize unsigned integer
+func readVarInt(r *bytes.Reader) (uint64, error) {
+	prefix, err := r.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+
+	var b [8]byte
+
+	switch prefix {
+	case 0xfd:
+		if _, err := io.ReadFull(r, b[:2]); err != nil {
+			return 0, err
+		}
+		return uint64(binary.LittleEndian.Uint16(b[:])), nil
+	case 0xfe:
+		if _, err := io.ReadFull(r, b[:4]); err != nil {
+			return 0, err
+		}
+		return uint64(binary.LittleEndian.Uint32(b[:])), nil
+	case 0xff:
+		if _, err := io.ReadFull(r, b[:8]); err != nil {
+			return 0, err
+		}
+		return binary.LittleEndian.Uint64(b[:]), nil
+	}
+
+	return uint64(prefix), nil
+}
@@ -0,0 +1,112 @@
+package bcrypto
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func TestSignMessage(t *testing.T) {
+	// signatures are deterministic since the nonce is generated by RFC6979
+	tests := []struct {
+		wif       string
+		address   string
+		text      string
+		signature string
+	}{
+		{
+			"5KYZdUEo39z3FPrtuX2QbbwGnNP5zTd7yyr2SC1j299sBCnWjss",
+			"1HZwkjkeaoZfTSaJxDw6aKkxp45agDiEzN",
+			"vires is numeris",
+			"GwxMsQJ+s7mMjDBiCFyRfHk5SOdPnfIQbszlBLNIBE6efexHARz5is8619IZ1XLlfXDlTzlYxaY/XaLRv/oKlxQ=",
+		},
+		{
+			"L4rK1yDtCWekvXuE6oXD9jCYfFNV2cWRpVuPLBcCU2z8TrisoyY1",
+			"1F3sAm6ZtwLAUnj7d38pGFxtP3RVEvtsbV",
+			"vires is numeris",
+			"HwxMsQJ+s7mMjDBiCFyRfHk5SOdPnfIQbszlBLNIBE6efexHARz5is8619IZ1XLlfXDlTzlYxaY/XaLRv/oKlxQ=",
+		},
+	}
+
+	for _, test := range tests {
+		pk, err := NewPrivateKeyFromWIF(test.wif)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		signature, err := SignMessage(pk, test.text)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if signature != test.signature {
+			t.Errorf("expect %s got %s", test.signature, signature)
+		}
+
+		address, err := ParseAddress(test.address)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ok, err := VerifyMessage(address, test.signature, test.text)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !ok {
+			t.Errorf("expect %s valid", test.signature)
+		}
+
+		ok, err = VerifyMessage(address, test.signature, test.text+"!")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if ok {
+			t.Errorf("expect %s invalid", test.signature)
+		}
+	}
+}
+
+func TestBadVerifyMessage(t *testing.T) {
+	address, _ := ParseAddress("1HZwkjkeaoZfTSaJxDw6aKkxp45agDiEzN")
+
+	_, err := VerifyMessage(address, "G5hpMA5p", "vires is numeris")
+	if err != ErrMessageBadSignature {
+		t.Errorf("expect ErrMessageBadSignature got %v", err)
+	}
+
+	_, err = VerifyMessage(address, "A"+strings.Repeat("A", 87), "vires is numeris")
+	if err != ErrMessageBadSignature {
+		t.Errorf("expect ErrMessageBadSignature got %v", err)
+	}
+
+	segwit, _ := ParseAddress("bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4")
+	_, err = VerifyMessage(segwit, "GwxMsQJ+s7mMjDBiCFyRfHk5SOdPnfIQbszlBLNIBE6efexHARz5is8619IZ1XLlfXDlTzlYxaY/XaLRv/oKlxQ=", "vires is numeris")
+	if err != ErrMessageBadAddress {
+		t.Errorf("expect ErrMessageBadAddress got %v", err)
+	}
+}
+
+func TestPutVarInt(t *testing.T) {
+	tests := []struct {
+		n      uint64
+		expect string
+	}{
+		{0, "00"},
+		{0xfc, "fc"},
+		{0xfd, "fdfd00"},
+		{0xffff, "fdffff"},
+		{0x10000, "fe00000100"},
+		{0x100000000, "ff0000000001000000"},
+	}
+
+	for _, test := range tests {
+		var buf bytes.Buffer
+		putVarInt(&buf, test.n)
+		if hex.EncodeToString(buf.Bytes()) != test.expect {
+			t.Errorf("expect %s got %x", test.expect, buf.Bytes())
+		}
+	}
+}
@@ -0,0 +1,328 @@
+package bcrypto
+
+import (
+	"errors"
+	"strings"
+	"sync"
+)
+
+type Network int
+
+const (
+	Mainet Network = iota
+	Testnet
+	Regtest
+	Signet
+)
+
+var (
+	// ErrNetworkUnknown represents the network is not registered
+	ErrNetworkUnknown = errors.New("unknown network")
+	// ErrNetworkDuplicate represents a network with the same name, or a version byte,
+	// bech32 human-readable part or extended key version in use, is already registered
+	ErrNetworkDuplicate = errors.New("duplicate network")
+	// ErrNetworkBadParams represents the network parameters can not be registered
+	ErrNetworkBadParams = errors.New("bad network params")
+)
+
+// NetworkParams describes the prefixes a network uses to serialize keys and addresses
+type NetworkParams struct {
+	Name string
+
+	// WIFPrefix is the version byte of WIF private keys
+	WIFPrefix byte
+	// PubKeyHashAddrID is the version byte of P2PKH addresses
+	PubKeyHashAddrID byte
+	// ScriptHashAddrID is the version byte of P2SH addresses
+	ScriptHashAddrID byte
+	// Bech32HRP is the human-readable part of segwit addresses
+	Bech32HRP string
+
+	// HDPrivateKeyID is the version of BIP32 extended private keys
+	HDPrivateKeyID [4]byte
+	// HDPublicKeyID is the version of BIP32 extended public keys
+	HDPublicKeyID [4]byte
+	// HDCoinType is the SLIP-44 coin type of BIP44 derivation paths
+	HDCoinType uint32
+	// HDVersions are the SLIP-132 extended key versions of the script types other than P2PKH
+	HDVersions []HDVersion
+}
+
+var (
+	MainnetParams = NetworkParams{
+		Name:             "mainnet",
+		WIFPrefix:        0x80,
+		PubKeyHashAddrID: 0x00,
+		ScriptHashAddrID: 0x05,
+		Bech32HRP:        "bc",
+		HDPrivateKeyID:   [4]byte{0x04, 0x88, 0xad, 0xe4},
+		HDPublicKeyID:    [4]byte{0x04, 0x88, 0xb2, 0x1e},
+		HDCoinType:       0,
+		HDVersions:       mainnetHDVersions,
+	}
+
+	TestnetParams = NetworkParams{
+		Name:             "testnet",
+		WIFPrefix:        0xef,
+		PubKeyHashAddrID: 0x6f,
+		ScriptHashAddrID: 0xc4,
+		Bech32HRP:        "tb",
+		HDPrivateKeyID:   [4]byte{0x04, 0x35, 0x83, 0x94},
+		HDPublicKeyID:    [4]byte{0x04, 0x35, 0x87, 0xcf},
+		HDCoinType:       1,
+		HDVersions:       testnetHDVersions,
+	}
+
+	RegtestParams = NetworkParams{
+		Name:             "regtest",
+		WIFPrefix:        0xef,
+		PubKeyHashAddrID: 0x6f,
+		ScriptHashAddrID: 0xc4,
+		Bech32HRP:        "bcrt",
+		HDPrivateKeyID:   [4]byte{0x04, 0x35, 0x83, 0x94},
+		HDPublicKeyID:    [4]byte{0x04, 0x35, 0x87, 0xcf},
+		HDCoinType:       1,
+		HDVersions:       testnetHDVersions,
+	}
+
+	SignetParams = NetworkParams{
+		Name:             "signet",
+		WIFPrefix:        0xef,
+		PubKeyHashAddrID: 0x6f,
+		ScriptHashAddrID: 0xc4,
+		Bech32HRP:        "tb",
+		HDPrivateKeyID:   [4]byte{0x04, 0x35, 0x83, 0x94},
+		HDPublicKeyID:    [4]byte{0x04, 0x35, 0x87, 0xcf},
+		HDCoinType:       1,
+		HDVersions:       testnetHDVersions,
+	}
+)
+
+// networks is indexed by Network, so the builtin networks must be registered
+// in the same order as their constants.
+// Several networks share prefixes (testnet, regtest and signet all use 0xef for WIF),
+// lookups by prefix return the earliest registered network.
+var networks = struct {
+	sync.RWMutex
+	params []NetworkParams
+}{
+	params: []NetworkParams{
+		MainnetParams.clone(),
+		TestnetParams.clone(),
+		RegtestParams.clone(),
+		SignetParams.clone(),
+	},
+}
+
+// RegisterNetwork adds the network parameters to the registry and returns its handle.
+// Lookups by prefix return the first match, so a network reusing the name, a version byte,
+// the bech32 human-readable part or an extended key version of another one would be
+// shadowed by it, and is rejected with ErrNetworkDuplicate. The HRP may be left empty
+// for networks without segwit.
+func RegisterNetwork(params NetworkParams) (Network, error) {
+	if err := params.check(); err != nil {
+		return 0, err
+	}
+
+	networks.Lock()
+	defer networks.Unlock()
+
+	for i := range networks.params {
+		if params.conflicts(&networks.params[i]) {
+			return 0, ErrNetworkDuplicate
+		}
+	}
+
+	networks.params = append(networks.params, params.clone())
+	return Network(len(networks.params) - 1), nil
+}
+
+// NetworkByName returns the network registered with the name
+func NetworkByName(name string) (Network, error) {
+	return findNetwork(func(p *NetworkParams) bool {
+		return strings.EqualFold(p.Name, name)
+	})
+}
+
+// NetworkByWIFPrefix returns the first network using the WIF prefix
+func NetworkByWIFPrefix(prefix byte) (Network, error) {
+	return findNetwork(func(p *NetworkParams) bool {
+		return p.WIFPrefix == prefix
+	})
+}
+
+// NetworkByHRP returns the first network using the bech32 human-readable part
+func NetworkByHRP(hrp string) (Network, error) {
+	return findNetwork(func(p *NetworkParams) bool {
+		return p.Bech32HRP == strings.ToLower(hrp)
+	})
+}
+
+// NetworkByAddressVersion returns the first network using the base58 address version
+// and the address kind implied by it
+func NetworkByAddressVersion(version byte) (Network, AddressType, error) {
+	kind := AddressP2PKH
+	network, err := findNetwork(func(p *NetworkParams) bool {
+		if p.PubKeyHashAddrID == version {
+			kind = AddressP2PKH
+			return true
+		}
+
+		if p.ScriptHashAddrID == version {
+			kind = AddressP2SH
+			return true
+		}
+
+		return false
+	})
+
+	return network, kind, err
+}
+
+// NetworkByHDPrivateKeyID returns the first network using the BIP32 private key version
+func NetworkByHDPrivateKeyID(id [4]byte) (Network, error) {
+	return findNetwork(func(p *NetworkParams) bool {
+		return p.HDPrivateKeyID == id
+	})
+}
+
+// NetworkByHDPublicKeyID returns the first network using the BIP32 public key version
+func NetworkByHDPublicKeyID(id [4]byte) (Network, error) {
+	return findNetwork(func(p *NetworkParams) bool {
+		return p.HDPublicKeyID == id
+	})
+}
+
+// NetworkByHDVersion returns the first network using the extended key version,
+// the script type implied by it and whether it is a private key version
+func NetworkByHDVersion(id [4]byte) (Network, ScriptType, bool, error) {
+	script := ScriptP2PKH
+	private := false
+	network, err := findNetwork(func(p *NetworkParams) bool {
+		if p.HDPrivateKeyID == id || p.HDPublicKeyID == id {
+			script, private = ScriptP2PKH, p.HDPrivateKeyID == id
+			return true
+		}
+
+		for _, version := range p.HDVersions {
+			if version.PrivateKeyID == id || version.PublicKeyID == id {
+				script, private = version.ScriptType, version.PrivateKeyID == id
+				return true
+			}
+		}
+
+		return false
+	})
+
+	return network, script, private, err
+}
+
+func findNetwork(match func(p *NetworkParams) bool) (Network, error) {
+	networks.RLock()
+	defer networks.RUnlock()
+
+	for i := range networks.params {
+		if match(&networks.params[i]) {
+			return Network(i), nil
+		}
+	}
+
+	return 0, ErrNetworkUnknown
+}
+
+// Params returns a copy of the parameters registered for the network
+func (n Network) Params() (NetworkParams, error) {
+	networks.RLock()
+	defer networks.RUnlock()
+
+	if n < 0 || int(n) >= len(networks.params) {
+		return NetworkParams{}, ErrNetworkUnknown
+	}
+
+	return networks.params[n].clone(), nil
+}
+
+// check rejects an empty name, a HRP that is not lowercase printable ASCII,
+// zero or reused extended key versions and equal address version bytes
+func (p *NetworkParams) check() error {
+	if strings.TrimSpace(p.Name) == "" || len(p.Bech32HRP) > bech32MaxLength-7 {
+		return ErrNetworkBadParams
+	}
+
+	for i := 0; i < len(p.Bech32HRP); i++ {
+		if c := p.Bech32HRP[i]; c < 33 || c > 126 || (c >= 'A' && c <= 'Z') {
+			return ErrNetworkBadParams
+		}
+	}
+
+	if p.PubKeyHashAddrID == p.ScriptHashAddrID {
+		return ErrNetworkBadParams
+	}
+
+	ids := p.hdIDs()
+	for i, id := range ids {
+		if id == [4]byte{} {
+			return ErrNetworkBadParams
+		}
+
+		for _, other := range ids[:i] {
+			if id == other {
+				return ErrNetworkBadParams
+			}
+		}
+	}
+
+	return nil
+}
+
+// conflicts reports whether a lookup could return other for a prefix of p
+func (p *NetworkParams) conflicts(other *NetworkParams) bool {
+	if strings.EqualFold(p.Name, other.Name) || p.WIFPrefix == other.WIFPrefix {
+		return true
+	}
+
+	if p.Bech32HRP != "" && p.Bech32HRP == other.Bech32HRP {
+		return true
+	}
+
+	for _, version := range []byte{p.PubKeyHashAddrID, p.ScriptHashAddrID} {
+		if version == other.PubKeyHashAddrID || version == other.ScriptHashAddrID {
+			return true
+		}
+	}
+
+	for _, id := range p.hdIDs() {
+		for _, otherID := range other.hdIDs() {
+			if id == otherID {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// hdIDs returns the BIP32 and SLIP-132 extended key versions
+func (p *NetworkParams) hdIDs() [][4]byte {
+	ids := [][4]byte{p.HDPrivateKeyID, p.HDPublicKeyID}
+	for _, version := range p.HDVersions {
+		ids = append(ids, version.PrivateKeyID, version.PublicKeyID)
+	}
+
+	return ids
+}
+
+// clone copies HDVersions so the registry never shares it with callers
+func (p NetworkParams) clone() NetworkParams {
+	p.HDVersions = append([]HDVersion(nil), p.HDVersions...)
+	return p
+}
+
+func (n Network) String() string {
+	params, err := n.Params()
+	if err != nil {
+		return "unknown"
+	}
+
+	return params.Name
+}
@@ -0,0 +1,208 @@
+package bcrypto
+
+import (
+	"encoding/hex"
+	"sync"
+	"testing"
+
+	. "github.com/detailyang/go-bprimitives"
+)
+
+var (
+	litecoinOnce    sync.Once
+	litecoinNetwork Network
+)
+
+func registerLitecoin(t *testing.T) Network {
+	litecoinOnce.Do(func() {
+		network, err := RegisterNetwork(NetworkParams{
+			Name:             "litecoin",
+			WIFPrefix:        0xb0,
+			PubKeyHashAddrID: 0x30,
+			ScriptHashAddrID: 0x32,
+			Bech32HRP:        "ltc",
+			HDPrivateKeyID:   [4]byte{0x01, 0x9d, 0x9c, 0xfe},
+			HDPublicKeyID:    [4]byte{0x01, 0x9d, 0xa4, 0x62},
+			HDCoinType:       2,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		litecoinNetwork = network
+	})
+
+	return litecoinNetwork
+}
+
+func TestNetworkLookup(t *testing.T) {
+	tests := []struct {
+		name    string
+		network Network
+	}{
+		{"mainnet", Mainet},
+		{"testnet", Testnet},
+		{"regtest", Regtest},
+		{"signet", Signet},
+	}
+
+	for _, test := range tests {
+		network, err := NetworkByName(test.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if network != test.network || network.String() != test.name {
+			t.Errorf("expect %s got %s", test.name, network)
+		}
+	}
+
+	network, err := NetworkByWIFPrefix(0xef)
+	if err != nil || network != Testnet {
+		t.Errorf("expect testnet got %s %v", network, err)
+	}
+
+	network, err = NetworkByHRP("bcrt")
+	if err != nil || network != Regtest {
+		t.Errorf("expect regtest got %s %v", network, err)
+	}
+
+	network, kind, err := NetworkByAddressVersion(0x05)
+	if err != nil || network != Mainet || kind != AddressP2SH {
+		t.Errorf("expect mainnet p2sh got %s %d %v", network, kind, err)
+	}
+
+	_, err = NetworkByWIFPrefix(0x00)
+	if err != ErrNetworkUnknown {
+		t.Errorf("expect ErrNetworkUnknown got %v", err)
+	}
+
+	_, err = Network(100).Params()
+	if err != ErrNetworkUnknown {
+		t.Errorf("expect ErrNetworkUnknown got %v", err)
+	}
+}
+
+func TestRegisterNetwork(t *testing.T) {
+	litecoin := registerLitecoin(t)
+
+	fork := func(f func(p *NetworkParams)) NetworkParams {
+		p := NetworkParams{
+			Name:             "fork",
+			WIFPrefix:        0xb1,
+			PubKeyHashAddrID: 0x31,
+			ScriptHashAddrID: 0x33,
+			Bech32HRP:        "fork",
+			HDPrivateKeyID:   [4]byte{0x0f, 0x0f, 0x0f, 0x01},
+			HDPublicKeyID:    [4]byte{0x0f, 0x0f, 0x0f, 0x02},
+		}
+		f(&p)
+		return p
+	}
+
+	bad := []struct {
+		params NetworkParams
+		expect error
+	}{
+		{fork(func(p *NetworkParams) { p.Name = "Litecoin" }), ErrNetworkDuplicate},
+		// a fork reusing the testnet WIF prefix would be shadowed by testnet
+		{fork(func(p *NetworkParams) { p.WIFPrefix = 0xef }), ErrNetworkDuplicate},
+		{fork(func(p *NetworkParams) { p.ScriptHashAddrID = 0x30 }), ErrNetworkDuplicate},
+		{fork(func(p *NetworkParams) { p.Bech32HRP = "tb" }), ErrNetworkDuplicate},
+		{fork(func(p *NetworkParams) { p.HDPublicKeyID = MainnetParams.HDPublicKeyID }), ErrNetworkDuplicate},
+		{fork(func(p *NetworkParams) { p.Name = "" }), ErrNetworkBadParams},
+		{fork(func(p *NetworkParams) { p.Bech32HRP = "Fork" }), ErrNetworkBadParams},
+		{fork(func(p *NetworkParams) { p.Bech32HRP = "fo rk" }), ErrNetworkBadParams},
+		{fork(func(p *NetworkParams) { p.HDPrivateKeyID = [4]byte{} }), ErrNetworkBadParams},
+		{fork(func(p *NetworkParams) { p.ScriptHashAddrID = p.PubKeyHashAddrID }), ErrNetworkBadParams},
+	}
+
+	for i, test := range bad {
+		if _, err := RegisterNetwork(test.params); err != test.expect {
+			t.Errorf("%d: expect %v got %v", i, test.expect, err)
+		}
+	}
+
+	secret, _ := NewHashFromReversedHexString("063377054c25f98bc538ac8dd2cf9064dd5d253a725ece0628a34e2f84803bd5")
+	expect, err := NewPrivateKeyFromHash(litecoin, secret, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pk, err := NewPrivateKeyFromBytes(expect.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if pk.Network != litecoin || pk.Hex() != expect.Hex() {
+		t.Errorf("expect %s got %s", expect, pk)
+	}
+
+	hash, _ := hex.DecodeString("751e76e8199196d454941c45d1b3a323f1433bd6")
+	tests := []struct {
+		kind   AddressType
+		expect string
+	}{
+		{AddressP2PKH, "LVuDpNCSSj6pQ7t9Pv6d6sUkLKoqDEVUnJ"},
+		{AddressP2WPKH, "ltc1qw508d6qejxtdg4y5r3zarvary0c5xw7kgmn4n9"},
+	}
+
+	for _, test := range tests {
+		address := NewAddress(test.kind, litecoin, hash)
+		if address.String() != test.expect {
+			t.Errorf("expect %s got %s", test.expect, address)
+		}
+
+		parsed, err := ParseAddress(test.expect)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if parsed.Network != litecoin || parsed.Kind != test.kind {
+			t.Errorf("expect %s %d got %s %d", litecoin, test.kind, parsed.Network, parsed.Kind)
+		}
+	}
+}
+
+func TestNetworkParamsCopy(t *testing.T) {
+	zpub := [4]byte{0x04, 0xb2, 0x47, 0x46}
+
+	params, err := Mainet.Params()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// editing the returned or the exported params leaves the registry as is
+	for i := range params.HDVersions {
+		params.HDVersions[i].PublicKeyID = [4]byte{}
+	}
+	params.HDVersions = append(params.HDVersions[:0], HDVersion{ScriptP2WPKH, [4]byte{1}, [4]byte{2}})
+
+	saved := MainnetParams.HDVersions[1]
+	MainnetParams.HDVersions[1].PublicKeyID = [4]byte{}
+	defer func() { MainnetParams.HDVersions[1] = saved }()
+
+	network, script, private, err := NetworkByHDVersion(zpub)
+	if err != nil || network != Mainet || script != ScriptP2WPKH || private {
+		t.Errorf("expect mainnet zpub got %s %s %t %v", network, script, private, err)
+	}
+
+	versions := []HDVersion{{ScriptP2WPKH, [4]byte{0x0a, 0x0b, 0x0c, 0x01}, [4]byte{0x0a, 0x0b, 0x0c, 0x02}}}
+	custom, err := RegisterNetwork(NetworkParams{
+		Name:             "copycoin",
+		WIFPrefix:        0x9a,
+		PubKeyHashAddrID: 0x1c,
+		ScriptHashAddrID: 0x1d,
+		Bech32HRP:        "copy",
+		HDPrivateKeyID:   [4]byte{0x0a, 0x0b, 0x0c, 0x03},
+		HDPublicKeyID:    [4]byte{0x0a, 0x0b, 0x0c, 0x04},
+		HDVersions:       versions,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	versions[0].PublicKeyID = [4]byte{}
+	if network, _, _, err := NetworkByHDVersion([4]byte{0x0a, 0x0b, 0x0c, 0x02}); err != nil || network != custom {
+		t.Errorf("expect %s got %s %v", custom, network, err)
+	}
+}
@@ -2,9 +2,16 @@ package bcrypto
 
 import (
 	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
 	"encoding/hex"
 	"errors"
+	"fmt"
+	"io"
+	"math/big"
 
+	"github.com/detailyang/go-bcrypto/secp256k1"
 	. "github.com/detailyang/go-bprimitives"
 )
 
@@ -12,20 +19,45 @@ var (
 	ErrPrivateBadFormat   = errors.New("bad private format")
 	ErrPrivateBadNetwork  = errors.New("bad private network")
 	ErrPrivateBadChecksum = errors.New("bad private checksum")
+	ErrPrivateBadSecret   = errors.New("bad private secret")
+	ErrPrivateBadHash     = errors.New("bad hash length")
+	ErrPrivateSignFailed  = errors.New("sign failed")
+	ErrPrivateBadCurve    = errors.New("bad private curve")
 )
 
+// maxRandomAttempts bounds the retries of NewPrivateKeyFromReader, the chance a
+// uniformly random 32-byte string is not a valid secret is below 2^-127
+const maxRandomAttempts = 16
+
+// PrivateKey is a secp256k1 secret on a network. The secret is kept behind a pointer
+// so copies of the key share it, its formatting methods redact it, RevealWIF returns
+// it and Destroy wipes it once the key is no longer needed.
 type PrivateKey struct {
 	Network    Network
-	Secret     Hash
+	secret     *Hash
 	Compressed bool
 }
 
-func NewPrivateKeyFromHash(network Network, secret Hash, compressed bool) *PrivateKey {
-	return &PrivateKey{
+// NewPrivateKeyFromHash returns the private key of the secret, which must be in [1, n-1].
+// The secret is copied, the caller still owns its Hash and wipes it when done.
+func NewPrivateKeyFromHash(network Network, secret Hash, compressed bool) (*PrivateKey, error) {
+	return newPrivateKey(network, secret[:], compressed)
+}
+
+// newPrivateKey copies the 32-byte secret into the key without passing it by value
+func newPrivateKey(network Network, secret []byte, compressed bool) (*PrivateKey, error) {
+	if len(secret) != 32 || !secp256k1.VerifySeckey(secret) {
+		return nil, ErrPrivateBadSecret
+	}
+
+	pk := &PrivateKey{
 		Network:    network,
-		Secret:     secret,
+		secret:     new(Hash),
 		Compressed: compressed,
 	}
+
+	copy(pk.secret[:], secret)
+	return pk, nil
 }
 
 func NewPrivateKeyFromHexString(hexstring string) (*PrivateKey, error) {
@@ -33,10 +65,25 @@ func NewPrivateKeyFromHexString(hexstring string) (*PrivateKey, error) {
 	if err != nil {
 		return nil, err
 	}
+	defer secp256k1.Zeroize(data)
+
+	return NewPrivateKeyFromBytes(data)
+}
+
+// NewPrivateKeyFromWIF decodes the private key from the wallet import format string
+// see https://en.bitcoin.it/wiki/Wallet_import_format
+func NewPrivateKeyFromWIF(wif string) (*PrivateKey, error) {
+	data, err := Base58Decode(wif)
+	if err != nil {
+		return nil, err
+	}
+	defer secp256k1.Zeroize(data)
 
 	return NewPrivateKeyFromBytes(data)
 }
 
+// NewPrivateKeyFromBytes decodes the private key from the base58 decoded WIF bytes:
+// prefix + secret + [compression flag] + checksum
 func NewPrivateKeyFromBytes(data []byte) (*PrivateKey, error) {
 	compressed := false
 	ndata := len(data)
@@ -49,83 +96,299 @@ func NewPrivateKeyFromBytes(data []byte) (*PrivateKey, error) {
 		return nil, ErrPrivateBadFormat
 	}
 
-	if compressed && data[data[ndata-5]] != 1 {
+	if !bytes.Equal(DHash256(data[:ndata-4]).TakeBytes(0, 4), data[ndata-4:]) {
+		return nil, ErrPrivateBadChecksum
+	}
+
+	if compressed && data[ndata-5] != 1 {
 		return nil, ErrPrivateBadFormat
 	}
 
-	buffer := NewReadBuffer(data)
-	network, err := buffer.GetUint8()
+	network, err := NetworkByWIFPrefix(data[0])
 	if err != nil {
-		return nil, err
+		return nil, ErrPrivateBadNetwork
 	}
 
-	switch network {
-	case 128:
-		network = uint8(Mainet)
-	case 239:
-		network = uint8(Testnet)
-	default:
-		return nil, ErrPrivateBadNetwork
+	return newPrivateKey(network, data[1:33], compressed)
+}
+
+// NewPrivateKeyFromRandom generates a private key from crypto/rand
+func NewPrivateKeyFromRandom(network Network, compressed bool) (*PrivateKey, error) {
+	return NewPrivateKeyFromReader(rand.Reader, network, compressed)
+}
+
+// NewPrivateKeyFromReader generates a private key from the reader,
+// retrying with fresh bytes when they are not a valid secret
+func NewPrivateKeyFromReader(reader io.Reader, network Network, compressed bool) (*PrivateKey, error) {
+	buf := make([]byte, 32)
+	defer secp256k1.Zeroize(buf)
+
+	for i := 0; i < maxRandomAttempts; i++ {
+		if _, err := io.ReadFull(reader, buf); err != nil {
+			return nil, err
+		}
+
+		if !secp256k1.VerifySeckey(buf) {
+			continue
+		}
+
+		return newPrivateKey(network, buf, compressed)
 	}
 
-	secret, err := buffer.GetHash()
+	return nil, ErrPrivateBadSecret
+}
+
+// Address derives the address of the kind paying to the key on its network
+func (pk *PrivateKey) Address(kind AddressType) (*Address, error) {
+	pubkey, err := pk.PublicKey()
 	if err != nil {
 		return nil, err
 	}
 
-	cs, err := buffer.GetBytes(4)
+	return pubkey.Address(pk.Network, kind)
+}
+
+// PublicKey returns the public key, compressed if the private key is
+func (pk *PrivateKey) PublicKey() (PublicKey, error) {
+	secret := pk.secretBytes()
+	defer secp256k1.Zeroize(secret)
+
+	pubkey, ok := secp256k1.CreatePubkeyFromBytes(secret, pk.Compressed)
+	if !ok {
+		return nil, errors.New("create pubkey failed")
+	}
+
+	return NewPublicKey(pubkey), nil
+}
+
+// NewPrivateKeyFromECDSA converts the standard library key, which must be on secp256k1.S256()
+func NewPrivateKeyFromECDSA(network Network, key *ecdsa.PrivateKey, compressed bool) (*PrivateKey, error) {
+	if key.Curve != secp256k1.S256() {
+		return nil, ErrPrivateBadCurve
+	}
+
+	if key.D.Sign() <= 0 || key.D.BitLen() > 256 {
+		return nil, ErrPrivateBadSecret
+	}
+
+	d := key.D.FillBytes(make([]byte, 32))
+	defer secp256k1.Zeroize(d)
+
+	return newPrivateKey(network, d, compressed)
+}
+
+// ToECDSA converts the private key to the standard library key on secp256k1.S256()
+func (pk *PrivateKey) ToECDSA() (*ecdsa.PrivateKey, error) {
+	pubkey, err := pk.PublicKey()
 	if err != nil {
 		return nil, err
 	}
 
-	if !bytes.Equal(DHash256(data[:ndata-4]).TakeBytes(0, 4), cs) {
-		return nil, ErrPrivateBadFormat
+	pub, err := pubkey.ToECDSA()
+	if err != nil {
+		return nil, err
 	}
 
-	return &PrivateKey{
-		Network:    Network(network),
-		Secret:     secret,
-		Compressed: compressed,
+	secret := pk.secretBytes()
+	defer secp256k1.Zeroize(secret)
+
+	return &ecdsa.PrivateKey{
+		PublicKey: *pub,
+		D:         new(big.Int).SetBytes(secret),
 	}, nil
 }
 
-func NewPrivateKeyFromRandom(network Network, compressed bool) *PrivateKey {
-	return &PrivateKey{
-		Network:    network,
-		Secret:     NewRandomHash(),
-		Compressed: compressed,
+// Public implements crypto.Signer, the returned key is an *ecdsa.PublicKey
+func (pk *PrivateKey) Public() crypto.PublicKey {
+	pubkey, err := pk.PublicKey()
+	if err != nil {
+		return nil
 	}
+
+	pub, err := pubkey.ToECDSA()
+	if err != nil {
+		return nil
+	}
+
+	return pub
 }
 
+// Sign implements crypto.Signer and returns the DER encoded ECDSA signature of the digest.
+// The nonce is generated by RFC6979 so rand is ignored, opts may be nil or carry
+// a hash function whose size matches the 32-byte digest.
+func (pk *PrivateKey) Sign(rand io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	if opts != nil && opts.HashFunc() != 0 && opts.HashFunc().Size() != len(digest) {
+		return nil, ErrPrivateBadHash
+	}
+
+	return pk.SignHash(digest)
+}
+
+// SignHash returns the DER encoded ECDSA signature of the 32-byte hash,
+// the nonce is generated by RFC6979
+func (pk *PrivateKey) SignHash(hash []byte) ([]byte, error) {
+	return pk.sign(hash, 0)
+}
+
+// SignRecoverable returns the 65-byte [R || S || V] signature of the 32-byte hash,
+// the public key can be recovered from it with RecoverPublicKey
+func (pk *PrivateKey) SignRecoverable(hash []byte) ([]byte, error) {
+	if len(hash) != 32 {
+		return nil, ErrPrivateBadHash
+	}
+
+	secret := pk.secretBytes()
+	defer secp256k1.Zeroize(secret)
+
+	return secp256k1.Sign(hash, secret)
+}
+
+// SignSchnorr returns the 64-byte BIP340 signature of the 32-byte hash,
+// auxRand is 32 bytes of fresh randomness or nil to sign deterministically
+func (pk *PrivateKey) SignSchnorr(hash, auxRand []byte) ([]byte, error) {
+	if len(hash) != 32 {
+		return nil, ErrPrivateBadHash
+	}
+
+	secret := pk.secretBytes()
+	defer secp256k1.Zeroize(secret)
+
+	return secp256k1.SchnorrSign(hash, secret, auxRand)
+}
+
+func (pk *PrivateKey) sign(hash []byte, testCase uint32) ([]byte, error) {
+	if len(hash) != 32 {
+		return nil, ErrPrivateBadHash
+	}
+
+	secret := pk.secretBytes()
+	defer secp256k1.Zeroize(secret)
+
+	sig, ok := secp256k1.Signature(hash, secret, testCase)
+	if !ok {
+		return nil, ErrPrivateSignFailed
+	}
+
+	return sig, nil
+}
+
+// Verify checks the DER encoded signature of the 32-byte hash against the public key
+func (pk *PrivateKey) Verify(hash, sig []byte) bool {
+	pubkey, err := pk.PublicKey()
+	if err != nil {
+		return false
+	}
+
+	return pubkey.Verify(hash, sig)
+}
+
+// secretBytes returns a copy of the 32-byte secret, callers wipe it with secp256k1.Zeroize
+func (pk *PrivateKey) secretBytes() []byte {
+	secret := make([]byte, 32)
+	if pk.secret != nil {
+		copy(secret, pk.secret[:])
+	}
+
+	return secret
+}
+
+// Layout returns the WIF bytes: prefix + secret + [compression flag] + checksum,
+// callers wipe them with secp256k1.Zeroize. It is nil if the network is not
+// registered or the key was destroyed.
 func (pk *PrivateKey) Layout() []byte {
-	buffer := NewBuffer()
-	if pk.Network == Mainet {
-		buffer.PutUint8(128)
-	} else {
-		buffer.PutUint8(239)
+	params, err := pk.Network.Params()
+	if err != nil || pk.secret == nil || *pk.secret == (Hash{}) {
+		return nil
 	}
 
-	buffer.PutHash(pk.Secret)
+	b := make([]byte, 0, 38)
+	b = append(b, params.WIFPrefix)
+	b = append(b, pk.secret[:]...)
 
 	if pk.Compressed {
-		buffer.PutUint8(1)
+		b = append(b, 1)
 	}
 
-	return buffer.PutCheckSum(4).Bytes()
+	return append(b, checksum(b)...)
 }
 
 func (pk *PrivateKey) Bytes() []byte {
 	return pk.Layout()
 }
 
+// RevealWIF returns the wallet import format string of the key.
+// Go strings can't be wiped, so call it only when the WIF must leave the process.
+func (pk *PrivateKey) RevealWIF() (string, error) {
+	if _, err := pk.Network.Params(); err != nil {
+		return "", ErrPrivateBadNetwork
+	}
+
+	secret := pk.secretBytes()
+	defer secp256k1.Zeroize(secret)
+
+	if !secp256k1.VerifySeckey(secret) {
+		return "", ErrPrivateBadSecret
+	}
+
+	return pk.Base58(), nil
+}
+
+// Base58 is the WIF of the key, kept for compatibility. It is empty
+// if the network is not registered or the key was destroyed.
 func (pk *PrivateKey) Base58() string {
-	return Base58Encode(pk.Layout())
+	layout := pk.Layout()
+	defer secp256k1.Zeroize(layout)
+
+	return Base58Encode(layout)
 }
 
+// Hex returns the hex of the WIF bytes, like Base58 it reveals the secret
+// and is empty if the key can't be encoded
 func (pk *PrivateKey) Hex() string {
-	return hex.EncodeToString(pk.Layout())
+	layout := pk.Layout()
+	defer secp256k1.Zeroize(layout)
+
+	return hex.EncodeToString(layout)
 }
 
+// Destroy wipes the secret shared by the copies of the key, none of them can sign afterwards
+func (pk *PrivateKey) Destroy() {
+	if pk.secret == nil {
+		return
+	}
+
+	secp256k1.Zeroize(pk.secret[:])
+	pk.secret = nil
+}
+
+// String redacts the secret
 func (pk *PrivateKey) String() string {
-	return pk.Hex()
+	return fmt.Sprintf("PrivateKey(%s, %s, [REDACTED])", pk.Network, compressionString(pk.Compressed))
+}
+
+// GoString redacts the secret for %#v
+func (pk *PrivateKey) GoString() string {
+	return fmt.Sprintf("bcrypto.PrivateKey{Network: %s, Secret: [REDACTED], Compressed: %t}", pk.Network, pk.Compressed)
+}
+
+// Format implements fmt.Formatter so that every verb, %x and %d included, is redacted
+func (pk *PrivateKey) Format(f fmt.State, verb rune) {
+	formatRedacted(f, verb, pk.String(), pk.GoString())
+}
+
+func formatRedacted(f fmt.State, verb rune, str, gostr string) {
+	if verb == 'v' && f.Flag('#') {
+		io.WriteString(f, gostr)
+		return
+	}
+
+	io.WriteString(f, str)
+}
+
+func compressionString(compressed bool) string {
+	if compressed {
+		return "compressed"
+	}
+
+	return "uncompressed"
 }
@@ -1,6 +1,14 @@
 package bcrypto
 
 import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
 	"testing"
 
 	. "github.com/detailyang/go-bprimitives"
@@ -12,10 +20,19 @@ func TestPrivateKey(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	pk := NewPrivateKeyFromHash(Mainet, secret, false)
+	pk, err := NewPrivateKeyFromHash(Mainet, secret, false)
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	if pk.Base58() != "5KSCKP8NUyBZPCCQusxRwgmz9sfvJQEgbGukmmHepWw5Bzp95mu" {
-		t.Errorf("expect %s got %s", "5KSCKP8NUyBZPCCQusxRwgmz9sfvJQEgbGukmmHepWw5Bzp95mu", pk.String())
+		t.Errorf("expect %s got %s", "5KSCKP8NUyBZPCCQusxRwgmz9sfvJQEgbGukmmHepWw5Bzp95mu", pk.Base58())
+	}
+
+	// the key owns a copy, destroying it leaves the caller's secret to the caller
+	pk.Destroy()
+	if secret == (Hash{}) {
+		t.Error("expect the caller's secret untouched")
 	}
 }
 
@@ -30,3 +47,409 @@ func TestPrivateKeyFromBytes(t *testing.T) {
 		t.Errorf("expect %s got %s", "5KSCKP8NUyBZPCCQusxRwgmz9sfvJQEgbGukmmHepWw5Bzp95mu", pk.Base58())
 	}
 }
+
+func TestPrivateKeyAddress(t *testing.T) {
+	secret, err := NewHashFromReversedHexString("0100000000000000000000000000000000000000000000000000000000000000")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pk, err := NewPrivateKeyFromHash(Mainet, secret, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	address, err := pk.Address(AddressP2PKH)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if address.String() != "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH" {
+		t.Errorf("expect %s got %s", "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH", address)
+	}
+
+	address, err = pk.Address(AddressP2WPKH)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if address.String() != "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4" {
+		t.Errorf("expect %s got %s", "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4", address)
+	}
+}
+
+func TestPrivateKeyFromWIF(t *testing.T) {
+	tests := []struct {
+		wif        string
+		network    Network
+		compressed bool
+	}{
+		{"5KSCKP8NUyBZPCCQusxRwgmz9sfvJQEgbGukmmHepWw5Bzp95mu", Mainet, false},
+		{"KwDiBf89QgGbjEhKnhXJuH7LrciVrZi3qYjgd9M7rFU73sVHnoWn", Mainet, true},
+		{"cMahea7zqjxrtgAbB7LSGbcQUr1uX1ojuat9jZodMN87JcbXMTcA", Testnet, true},
+	}
+
+	for _, test := range tests {
+		pk, err := NewPrivateKeyFromWIF(test.wif)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if pk.Network != test.network || pk.Compressed != test.compressed {
+			t.Errorf("expect %s %v got %s %v", test.network, test.compressed, pk.Network, pk.Compressed)
+		}
+
+		if pk.Base58() != test.wif {
+			t.Errorf("expect %s got %s", test.wif, pk.Base58())
+		}
+	}
+}
+
+func TestBadPrivateKeyFromWIF(t *testing.T) {
+	_, err := NewPrivateKeyFromWIF("KwDiBf89QgGbjEhKnhXJuH7LrciVrZi3qYjgd9M7rFU73sVHnoWo")
+	if err != ErrPrivateBadChecksum {
+		t.Errorf("expect ErrPrivateBadChecksum got %v", err)
+	}
+
+	_, err = NewPrivateKeyFromWIF("KwDiBf89QgGbjEhKnhXJuH7LrciVrZi3qYjgd9M7rFU73sVHnoW0")
+	if err != ErrInvalidCharacter {
+		t.Errorf("expect ErrInvalidCharacter got %v", err)
+	}
+
+	secret := make([]byte, 32)
+	secret[31] = 1
+
+	_, err = NewPrivateKeyFromWIF(Base58EncodeCheck(append(secret, 0x02), 0x80))
+	if err != ErrPrivateBadFormat {
+		t.Errorf("expect ErrPrivateBadFormat got %v", err)
+	}
+
+	_, err = NewPrivateKeyFromWIF(Base58EncodeCheck(secret, 0x00))
+	if err != ErrPrivateBadNetwork {
+		t.Errorf("expect ErrPrivateBadNetwork got %v", err)
+	}
+
+	_, err = NewPrivateKeyFromWIF(Base58EncodeCheck(secret[1:], 0x80))
+	if err != ErrPrivateBadFormat {
+		t.Errorf("expect ErrPrivateBadFormat got %v", err)
+	}
+}
+
+func TestPrivateKeyBadSecret(t *testing.T) {
+	tests := []string{
+		"0000000000000000000000000000000000000000000000000000000000000000",
+		"fffffffffffffffffffffffffffffffebaaedce6af48a03bbfd25e8cd0364141",
+		"fffffffffffffffffffffffffffffffebaaedce6af48a03bbfd25e8cd0364142",
+		"ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff",
+	}
+
+	for _, test := range tests {
+		data, _ := hex.DecodeString(test)
+		secret, err := NewReadBuffer(data).GetHash()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = NewPrivateKeyFromHash(Mainet, secret, true)
+		if err != ErrPrivateBadSecret {
+			t.Errorf("%s: expect ErrPrivateBadSecret got %v", test, err)
+		}
+
+		_, err = NewPrivateKeyFromWIF(Base58EncodeCheck(append(data, 0x01), 0x80))
+		if err != ErrPrivateBadSecret {
+			t.Errorf("%s: expect ErrPrivateBadSecret got %v", test, err)
+		}
+	}
+}
+
+func TestPrivateKeyFromReader(t *testing.T) {
+	invalid, _ := hex.DecodeString("fffffffffffffffffffffffffffffffebaaedce6af48a03bbfd25e8cd0364141")
+	valid, _ := hex.DecodeString("063377054c25f98bc538ac8dd2cf9064dd5d253a725ece0628a34e2f84803bd5")
+
+	pk, err := NewPrivateKeyFromReader(bytes.NewReader(append(invalid, valid...)), Mainet, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(pk.secretBytes(), valid) {
+		t.Errorf("expect %x got %x", valid, pk.secretBytes())
+	}
+
+	_, err = NewPrivateKeyFromReader(bytes.NewReader(make([]byte, 32*maxRandomAttempts)), Mainet, true)
+	if err != ErrPrivateBadSecret {
+		t.Errorf("expect ErrPrivateBadSecret got %v", err)
+	}
+
+	_, err = NewPrivateKeyFromReader(bytes.NewReader(valid[:16]), Mainet, true)
+	if err != io.ErrUnexpectedEOF {
+		t.Errorf("expect io.ErrUnexpectedEOF got %v", err)
+	}
+
+	_, err = NewPrivateKeyFromRandom(Testnet, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPrivateKeySign(t *testing.T) {
+	secret, err := NewHashFromReversedHexString("0100000000000000000000000000000000000000000000000000000000000000")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pk, err := NewPrivateKeyFromHash(Mainet, secret, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hash := sha256.Sum256([]byte("Satoshi Nakamoto"))
+	sig, err := pk.SignHash(hash[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// deterministic nonce, see RFC6979
+	expect := "3045022100934b1ea10a4b3c1757e2b0c017d0b6143ce3c9a7e6a4a49860d7a6ab210ee3d802202442ce9d2b916064108014783e923ec36b49743e2ffa1c4496f01a512aafd9e5"
+	if hex.EncodeToString(sig) != expect {
+		t.Errorf("expect %s got %x", expect, sig)
+	}
+
+	if !pk.Verify(hash[:], sig) {
+		t.Error("expect signature valid")
+	}
+
+	pubkey, err := pk.PublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !pubkey.Verify(hash[:], sig) {
+		t.Error("expect signature valid")
+	}
+
+	hash[0] ^= 1
+	if pk.Verify(hash[:], sig) {
+		t.Error("expect signature invalid")
+	}
+
+	_, err = pk.SignHash(hash[:31])
+	if err != ErrPrivateBadHash {
+		t.Errorf("expect ErrPrivateBadHash got %v", err)
+	}
+
+	ksig, ok := NewKeyFromPrivateKey(pk).Signature(hash[:], 0)
+	if !ok {
+		t.Fatal("expect key signature")
+	}
+
+	if !pk.Verify(hash[:], ksig) {
+		t.Error("expect key signature valid")
+	}
+}
+
+func TestPrivateKeySignSchnorr(t *testing.T) {
+	// BIP340 test vector 0
+	secret, err := NewHashFromReversedHexString("0300000000000000000000000000000000000000000000000000000000000000")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pk, err := NewPrivateKeyFromHash(Mainet, secret, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg := make([]byte, 32)
+	sig, err := pk.SignSchnorr(msg, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := "e907831f80848d1069a5371b402410364bdf1c5f8307b0084c55f1ce2dca821525f66a4a85ea8b71e482a74f382d2ce5ebeee8fdb2172f477df4900d310536c0"
+	if hex.EncodeToString(sig) != expect {
+		t.Errorf("expect %s got %x", expect, sig)
+	}
+
+	pubkey, err := pk.PublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !pubkey.VerifySchnorr(msg, sig) {
+		t.Error("expect signature valid")
+	}
+
+	msg[0] ^= 1
+	if pubkey.VerifySchnorr(msg, sig) {
+		t.Error("expect signature invalid")
+	}
+
+	_, err = pk.SignSchnorr(msg[:31], nil)
+	if err != ErrPrivateBadHash {
+		t.Errorf("expect ErrPrivateBadHash got %v", err)
+	}
+}
+
+func TestPrivateKeySigner(t *testing.T) {
+	pk, err := NewPrivateKeyFromWIF("KwDiBf89QgGbjEhKnhXJuH7LrciVrZi3qYjgd9M7rFU73sVHnoWn")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var signer crypto.Signer = pk
+	hash := sha256.Sum256([]byte("Satoshi Nakamoto"))
+	sig, err := signer.Sign(nil, hash[:], crypto.SHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pub, ok := signer.Public().(*ecdsa.PublicKey)
+	if !ok {
+		t.Fatalf("expect *ecdsa.PublicKey got %T", signer.Public())
+	}
+
+	if !ecdsa.VerifyASN1(pub, hash[:], sig) {
+		t.Error("expect signature valid")
+	}
+
+	_, err = signer.Sign(nil, hash[:], crypto.SHA512)
+	if err != ErrPrivateBadHash {
+		t.Errorf("expect ErrPrivateBadHash got %v", err)
+	}
+}
+
+func TestPrivateKeyECDSA(t *testing.T) {
+	pk, err := NewPrivateKeyFromWIF("KwDiBf89QgGbjEhKnhXJuH7LrciVrZi3qYjgd9M7rFU73sVHnoWn")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := pk.ToECDSA()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if key.D.Int64() != 1 || key.X.Text(16) != "79be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798" {
+		t.Errorf("expect generator got %x %x", key.D, key.X)
+	}
+
+	converted, err := NewPrivateKeyFromECDSA(Mainet, key, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if converted.Base58() != pk.Base58() {
+		t.Errorf("expect %s got %s", pk.Base58(), converted.Base58())
+	}
+
+	pubkey, err := NewPublicKeyFromECDSA(&key.PublicKey, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if pubkey.String() != "0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798" {
+		t.Errorf("expect %s got %s", "0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798", pubkey)
+	}
+
+	key.Curve = nil
+	_, err = NewPrivateKeyFromECDSA(Mainet, key, true)
+	if err != ErrPrivateBadCurve {
+		t.Errorf("expect ErrPrivateBadCurve got %v", err)
+	}
+}
+
+func TestPrivateKeyRedact(t *testing.T) {
+	wif := "KwDiBf89QgGbjEhKnhXJuH7LrciVrZi3qYjgd9M7rFU73sVHnoWn"
+	pk, err := NewPrivateKeyFromWIF(wif)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if revealed, err := pk.RevealWIF(); err != nil || revealed != wif {
+		t.Errorf("expect %s got %s %v", wif, revealed, err)
+	}
+
+	expect := "PrivateKey(mainnet, compressed, [REDACTED])"
+	if pk.String() != expect {
+		t.Errorf("expect %s got %s", expect, pk.String())
+	}
+
+	secret := hex.EncodeToString(pk.secret[:])
+
+	for _, format := range []string{"%v", "%+v", "%#v", "%s", "%x", "%X", "%q", "%d"} {
+		out := fmt.Sprintf(format, pk)
+		if !strings.Contains(out, "[REDACTED]") {
+			t.Errorf("%s: expect redacted got %s", format, out)
+		}
+
+		// values and wrapping structs print the pointer to the secret
+		for _, arg := range []interface{}{pk, *pk, struct{ Key PrivateKey }{*pk}, struct{ Key *PrivateKey }{pk}} {
+			out := fmt.Sprintf(format, arg)
+			if strings.Contains(out, wif) || strings.Contains(strings.ToLower(out), secret) {
+				t.Errorf("%s: expect no secret got %s", format, out)
+			}
+		}
+	}
+
+	if out := fmt.Sprintf("%#v", pk); !strings.HasPrefix(out, "bcrypto.PrivateKey{") {
+		t.Errorf("expect GoString got %s", out)
+	}
+}
+
+func TestPrivateKeyDestroy(t *testing.T) {
+	pk, err := NewPrivateKeyFromWIF("KwDiBf89QgGbjEhKnhXJuH7LrciVrZi3qYjgd9M7rFU73sVHnoWn")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	copied := *pk
+	shared := pk.secret
+
+	pk.Destroy()
+	if pk.secret != nil || *shared != (Hash{}) {
+		t.Errorf("expect wiped secret got %x", *shared)
+	}
+
+	if !bytes.Equal(copied.secretBytes(), make([]byte, 32)) {
+		t.Errorf("expect the copy wiped got %x", copied.secretBytes())
+	}
+
+	hash := sha256.Sum256([]byte("destroyed"))
+	if _, err := pk.SignHash(hash[:]); err != ErrPrivateSignFailed {
+		t.Errorf("expect ErrPrivateSignFailed got %v", err)
+	}
+
+	if _, err := pk.PublicKey(); err == nil {
+		t.Error("expect destroyed key has no public key")
+	}
+}
+
+func TestPrivateKeyRevealWIF(t *testing.T) {
+	pk, err := NewPrivateKeyFromWIF("KwDiBf89QgGbjEhKnhXJuH7LrciVrZi3qYjgd9M7rFU73sVHnoWn")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	unknown := *pk
+	unknown.Network = Network(-1)
+	if _, err := unknown.RevealWIF(); err != ErrPrivateBadNetwork {
+		t.Errorf("expect ErrPrivateBadNetwork got %v", err)
+	}
+
+	if unknown.Base58() != "" || unknown.Hex() != "" {
+		t.Errorf("expect empty encodings got %s %s", unknown.Base58(), unknown.Hex())
+	}
+
+	copied := *pk
+	pk.Destroy()
+
+	for _, key := range []*PrivateKey{pk, &copied} {
+		if _, err := key.RevealWIF(); err != ErrPrivateBadSecret {
+			t.Errorf("expect ErrPrivateBadSecret got %v", err)
+		}
+
+		if key.Base58() != "" || key.Layout() != nil {
+			t.Errorf("expect empty encodings got %s", key.Base58())
+		}
+	}
+}
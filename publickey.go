@@ -1,22 +1,103 @@
 package bcrypto
 
 import (
+	"crypto/ecdsa"
 	"encoding/hex"
+	"errors"
 
 	"github.com/detailyang/go-bcrypto/secp256k1"
 	. "github.com/detailyang/go-bprimitives"
 )
 
+var (
+	// ErrAddressUncompressedKey represents a segwit address is requested for an uncompressed key
+	ErrAddressUncompressedKey = errors.New("segwit address requires a compressed public key")
+	// ErrPublicBadFormat represents the bytes are not a point on secp256k1
+	ErrPublicBadFormat = errors.New("bad public format")
+	// ErrPublicBadCurve represents the standard library key is not on secp256k1
+	ErrPublicBadCurve = errors.New("bad public curve")
+)
+
 type PublicKey []byte
 
 func NewPublicKey(d []byte) PublicKey {
 	return PublicKey(d)
 }
 
+// NewPublicKeyFromECDSA converts the standard library key, which must be on secp256k1.S256()
+func NewPublicKeyFromECDSA(key *ecdsa.PublicKey, compressed bool) (PublicKey, error) {
+	curve := secp256k1.S256()
+	if key.Curve != curve {
+		return nil, ErrPublicBadCurve
+	}
+
+	if key.X == nil || key.Y == nil || !curve.IsOnCurve(key.X, key.Y) {
+		return nil, ErrPublicBadFormat
+	}
+
+	data := curve.Marshal(key.X, key.Y)
+	if compressed {
+		var ok bool
+		data, ok = secp256k1.ReencodePubkey(data, true)
+		if !ok {
+			return nil, ErrPublicBadFormat
+		}
+	}
+
+	return NewPublicKey(data), nil
+}
+
+// ToECDSA converts the public key to the standard library key on secp256k1.S256()
+func (p PublicKey) ToECDSA() (*ecdsa.PublicKey, error) {
+	data, ok := secp256k1.ReencodePubkey(p, false)
+	if !ok {
+		return nil, ErrPublicBadFormat
+	}
+
+	x, y := secp256k1.S256().Unmarshal(data)
+	if x == nil {
+		return nil, ErrPublicBadFormat
+	}
+
+	return &ecdsa.PublicKey{
+		Curve: secp256k1.S256(),
+		X:     x,
+		Y:     y,
+	}, nil
+}
+
+// RecoverPublicKey recovers the signer of the 32-byte hash from the 65-byte [R || S || V] signature
+func RecoverPublicKey(hash, sig []byte, compressed bool) (PublicKey, error) {
+	pubkey, err := secp256k1.RecoverPubkey(hash, sig)
+	if err != nil {
+		return nil, err
+	}
+
+	if compressed {
+		var ok bool
+		pubkey, ok = secp256k1.ReencodePubkey(pubkey, true)
+		if !ok {
+			return nil, ErrPublicBadFormat
+		}
+	}
+
+	return NewPublicKey(pubkey), nil
+}
+
 func (p PublicKey) Verify(msg, sig []byte) bool {
 	return secp256k1.VerifySignature(p, msg, sig)
 }
 
+// VerifySchnorr checks the 64-byte BIP340 signature of the message against the
+// x coordinate of the public key
+func (p PublicKey) VerifySchnorr(msg, sig []byte) bool {
+	if len(p) == 0 || p.Length() != len(p) {
+		return false
+	}
+
+	return secp256k1.SchnorrVerify(p[1:33], msg, sig)
+}
+
 func (p PublicKey) Length() int {
 	if p[0] == 2 || p[0] == 3 {
 		return 33
@@ -62,3 +143,31 @@ func (p PublicKey) IsCompressed() bool {
 
 	return true
 }
+
+// Address derives the address of the kind paying to the public key.
+// AddressP2SH is the nested segwit P2SH-P2WPKH address, AddressP2TR is the
+// BIP86 key-path only address. SegWit addresses require a compressed key.
+func (p PublicKey) Address(network Network, kind AddressType) (*Address, error) {
+	if kind != AddressP2PKH && !p.IsCompressed() {
+		return nil, ErrAddressUncompressedKey
+	}
+
+	switch kind {
+	case AddressP2PKH:
+		return NewAddress(kind, network, p.ID()), nil
+	case AddressP2SH:
+		// redeem script: OP_0 <20-byte key hash>
+		script := append([]byte{0x00, AddressHashSize}, p.ID()...)
+		return NewAddress(kind, network, Hash160(script)), nil
+	case AddressP2WPKH:
+		return NewAddress(kind, network, p.ID()), nil
+	case AddressP2TR:
+		output, err := taprootOutputKey(p)
+		if err != nil {
+			return nil, err
+		}
+		return NewAddress(kind, network, output), nil
+	}
+
+	return nil, ErrAddressBadKind
+}
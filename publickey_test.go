@@ -0,0 +1,49 @@
+package bcrypto
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+func TestPublicKeyAddress(t *testing.T) {
+	tests := []struct {
+		pubkey  string
+		network Network
+		kind    AddressType
+		expect  string
+	}{
+		{"0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798", Mainet, AddressP2PKH, "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH"},
+		{"0479be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798483ada7726a3c4655da4fbfc0e1108a8fd17b448a68554199c47d08ffb10d4b8", Mainet, AddressP2PKH, "1EHNa6Q4Jz2uvNExL497mE43ikXhwF6kZm"},
+		{"0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798", Mainet, AddressP2SH, "3JvL6Ymt8MVWiCNHC7oWU6nLeHNJKLZGLN"},
+		{"0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798", Mainet, AddressP2WPKH, "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4"},
+		{"0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798", Testnet, AddressP2WPKH, "tb1qw508d6qejxtdg4y5r3zarvary0c5xw7kxpjzsx"},
+		// https://github.com/bitcoin/bips/blob/master/bip-0086.mediawiki#test-vectors
+		{"02cc8a4bc64d897bddc5fbc2f670f7a8ba0b386779106cf1223c6fc5d7cd6fc115", Mainet, AddressP2TR, "bc1p5cyxnuxmeuwuvkwfem96lqzszd02n6xdcjrs20cac6yqjjwudpxqkedrcr"},
+	}
+
+	for _, test := range tests {
+		data, _ := hex.DecodeString(test.pubkey)
+		address, err := NewPublicKey(data).Address(test.network, test.kind)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if address.String() != test.expect {
+			t.Errorf("expect %s got %s", test.expect, address)
+		}
+	}
+
+	data, _ := hex.DecodeString("0479be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798483ada7726a3c4655da4fbfc0e1108a8fd17b448a68554199c47d08ffb10d4b8")
+	for _, kind := range []AddressType{AddressP2SH, AddressP2WPKH, AddressP2TR} {
+		_, err := NewPublicKey(data).Address(Mainet, kind)
+		if err != ErrAddressUncompressedKey {
+			t.Errorf("expect ErrAddressUncompressedKey got %v", err)
+		}
+	}
+
+	data, _ = hex.DecodeString("0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798")
+	_, err := NewPublicKey(data).Address(Mainet, AddressP2WSH)
+	if err != ErrAddressBadKind {
+		t.Errorf("expect ErrAddressBadKind got %v", err)
+	}
+}
@@ -0,0 +1,199 @@
+// BIP340 Schnorr signatures and x-only key helpers built from the internals of the vendored libsecp256k1,
+// which predates the upstream schnorrsig and extrakeys modules.
+// see https://github.com/bitcoin/bips/blob/master/bip-0340.mediawiki
+
+// secp256k1_ext_tagged_sha256_init initializes hash with SHA256(tag) || SHA256(tag).
+static void secp256k1_ext_tagged_sha256_init(secp256k1_sha256_t *hash, const char *tag) {
+	unsigned char taghash[32];
+
+	secp256k1_sha256_initialize(hash);
+	secp256k1_sha256_write(hash, (const unsigned char *)tag, strlen(tag));
+	secp256k1_sha256_finalize(hash, taghash);
+
+	secp256k1_sha256_initialize(hash);
+	secp256k1_sha256_write(hash, taghash, 32);
+	secp256k1_sha256_write(hash, taghash, 32);
+}
+
+// secp256k1_ext_xonly_load lifts a 32-byte x coordinate to the point with even y.
+//
+// Returns: 1: the x coordinate is on the curve
+//          0: the x coordinate is not on the curve or not below the field size
+static int secp256k1_ext_xonly_load(secp256k1_ge *ge, const unsigned char *x32) {
+	secp256k1_fe x;
+
+	if (!secp256k1_fe_set_b32(&x, x32)) {
+		return 0;
+	}
+	return secp256k1_ge_set_xo_var(ge, &x, 0);
+}
+
+// secp256k1_ext_schnorr_challenge computes e = int(hash_BIP0340/challenge(r || p || msg)) mod n.
+static void secp256k1_ext_schnorr_challenge(
+	secp256k1_scalar *e,
+	const unsigned char *r32,
+	const unsigned char *p32,
+	const unsigned char *msg,
+	size_t msglen
+) {
+	secp256k1_sha256_t hash;
+	unsigned char buf[32];
+
+	secp256k1_ext_tagged_sha256_init(&hash, "BIP0340/challenge");
+	secp256k1_sha256_write(&hash, r32, 32);
+	secp256k1_sha256_write(&hash, p32, 32);
+	secp256k1_sha256_write(&hash, msg, msglen);
+	secp256k1_sha256_finalize(&hash, buf);
+	secp256k1_scalar_set_b32(e, buf, NULL);
+}
+
+// secp256k1_ext_schnorr_sign creates a BIP340 signature.
+//
+// Returns: 1: signing was successful
+//          0: the secret key or the derived nonce was invalid
+// Args:    ctx:     pointer to a context object initialized for signing (cannot be NULL)
+//  Out:    sig64:   the 64-byte signature (cannot be NULL)
+//  In:     msg32:   the 32-byte message (cannot be NULL)
+//          seckey:  the 32-byte secret key (cannot be NULL)
+//          aux32:   32 bytes of auxiliary randomness (cannot be NULL)
+static int secp256k1_ext_schnorr_sign(
+	const secp256k1_context* ctx,
+	unsigned char *sig64,
+	const unsigned char *msg32,
+	const unsigned char *seckey,
+	const unsigned char *aux32
+) {
+	secp256k1_scalar d, k, e;
+	secp256k1_gej pj, rj;
+	secp256k1_ge p, r;
+	secp256k1_sha256_t hash;
+	unsigned char px[32], t[32], buf[32];
+	int overflow = 0;
+	int i, ret = 0;
+
+	secp256k1_scalar_set_b32(&d, seckey, &overflow);
+	if (overflow || secp256k1_scalar_is_zero(&d)) {
+		goto done;
+	}
+
+	secp256k1_ecmult_gen(&ctx->ecmult_gen_ctx, &pj, &d);
+	secp256k1_ge_set_gej(&p, &pj);
+	secp256k1_fe_normalize(&p.x);
+	secp256k1_fe_normalize(&p.y);
+	secp256k1_scalar_cond_negate(&d, secp256k1_fe_is_odd(&p.y));
+	secp256k1_fe_get_b32(px, &p.x);
+
+	// t = bytes(d) xor hash_BIP0340/aux(a)
+	secp256k1_ext_tagged_sha256_init(&hash, "BIP0340/aux");
+	secp256k1_sha256_write(&hash, aux32, 32);
+	secp256k1_sha256_finalize(&hash, buf);
+	secp256k1_scalar_get_b32(t, &d);
+	for (i = 0; i < 32; i++) {
+		t[i] ^= buf[i];
+	}
+
+	// k = int(hash_BIP0340/nonce(t || bytes(P) || m)) mod n
+	secp256k1_ext_tagged_sha256_init(&hash, "BIP0340/nonce");
+	secp256k1_sha256_write(&hash, t, 32);
+	secp256k1_sha256_write(&hash, px, 32);
+	secp256k1_sha256_write(&hash, msg32, 32);
+	secp256k1_sha256_finalize(&hash, buf);
+	secp256k1_scalar_set_b32(&k, buf, NULL);
+	if (secp256k1_scalar_is_zero(&k)) {
+		goto done;
+	}
+
+	secp256k1_ecmult_gen(&ctx->ecmult_gen_ctx, &rj, &k);
+	secp256k1_ge_set_gej(&r, &rj);
+	secp256k1_fe_normalize(&r.x);
+	secp256k1_fe_normalize(&r.y);
+	secp256k1_scalar_cond_negate(&k, secp256k1_fe_is_odd(&r.y));
+	secp256k1_fe_get_b32(sig64, &r.x);
+
+	// s = (k + e*d) mod n
+	secp256k1_ext_schnorr_challenge(&e, sig64, px, msg32, 32);
+	secp256k1_scalar_mul(&e, &e, &d);
+	secp256k1_scalar_add(&e, &e, &k);
+	secp256k1_scalar_get_b32(sig64 + 32, &e);
+	ret = 1;
+
+done:
+	secp256k1_scalar_clear(&d);
+	secp256k1_scalar_clear(&k);
+	memset(t, 0, sizeof(t));
+	memset(buf, 0, sizeof(buf));
+	return ret;
+}
+
+// secp256k1_ext_schnorr_verify verifies a BIP340 signature.
+//
+// Returns: 1: signature is valid
+//          0: signature is invalid
+// Args:    ctx:      pointer to a context object initialized for verification (cannot be NULL)
+//  In:     sig64:    the 64-byte signature (cannot be NULL)
+//          msg:      the message (cannot be NULL unless msglen is 0)
+//          msglen:   length of msg
+//          xonly32:  the 32-byte x-only public key (cannot be NULL)
+static int secp256k1_ext_schnorr_verify(
+	const secp256k1_context* ctx,
+	const unsigned char *sig64,
+	const unsigned char *msg,
+	size_t msglen,
+	const unsigned char *xonly32
+) {
+	secp256k1_scalar s, e;
+	secp256k1_gej pj, rj;
+	secp256k1_ge p, r;
+	secp256k1_fe rx;
+	int overflow = 0;
+
+	if (!secp256k1_fe_set_b32(&rx, sig64)) {
+		return 0;
+	}
+	secp256k1_scalar_set_b32(&s, sig64 + 32, &overflow);
+	if (overflow) {
+		return 0;
+	}
+	if (!secp256k1_ext_xonly_load(&p, xonly32)) {
+		return 0;
+	}
+
+	// R = s*G - e*P
+	secp256k1_ext_schnorr_challenge(&e, sig64, xonly32, msg, msglen);
+	secp256k1_scalar_negate(&e, &e);
+	secp256k1_gej_set_ge(&pj, &p);
+	secp256k1_ecmult(&ctx->ecmult_ctx, &rj, &pj, &e, &s);
+
+	secp256k1_ge_set_gej_var(&r, &rj);
+	if (secp256k1_ge_is_infinity(&r)) {
+		return 0;
+	}
+	secp256k1_fe_normalize_var(&r.x);
+	secp256k1_fe_normalize_var(&r.y);
+	if (secp256k1_fe_is_odd(&r.y)) {
+		return 0;
+	}
+	return secp256k1_fe_equal_var(&rx, &r.x);
+}
+
+// secp256k1_ext_seckey_negate replaces the secret key with n - seckey in constant time.
+//
+// Returns: 1: the secret key was negated
+//          0: the secret key was invalid (zero or overflow)
+// Args:    ctx:     pointer to a context object (cannot be NULL)
+//  In/Out: seckey:  the 32-byte secret key (cannot be NULL)
+static int secp256k1_ext_seckey_negate(const secp256k1_context* ctx, unsigned char *seckey) {
+	secp256k1_scalar s;
+	int overflow = 0;
+	int ret = 0;
+	(void)ctx;
+
+	secp256k1_scalar_set_b32(&s, seckey, &overflow);
+	if (!overflow && !secp256k1_scalar_is_zero(&s)) {
+		secp256k1_scalar_negate(&s, &s);
+		secp256k1_scalar_get_b32(seckey, &s);
+		ret = 1;
+	}
+	secp256k1_scalar_clear(&s);
+	return ret;
+}
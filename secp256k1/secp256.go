@@ -17,11 +17,21 @@ package secp256k1
 #include "./libsecp256k1/src/secp256k1.c"
 #include "./libsecp256k1/src/modules/recovery/main_impl.h"
 #include "ext.h"
+#include "schnorr.h"
 
 typedef void (*callbackFunc) (const char* msg, void* data);
 extern void secp256k1GoPanicIllegal(const char* msg, void* data);
 extern void secp256k1GoPanicError(const char* msg, void* data);
 
+// secp256k1_go_memclear writes zeros through a volatile pointer so the
+// compiler cannot drop the stores as dead
+static void secp256k1_go_memclear(void *ptr, size_t len) {
+    volatile unsigned char *p = (volatile unsigned char *)ptr;
+    while (len--) {
+        *p++ = 0;
+    }
+}
+
 static int ecdsa_signature_parse_der_lax(const secp256k1_context* ctx, secp256k1_ecdsa_signature* sig, const unsigned char *input, size_t inputlen) {
     size_t rpos, rlen, spos, slen;
     size_t pos = 0;
@@ -153,9 +163,20 @@ static int ecdsa_signature_parse_der_lax(const secp256k1_context* ctx, secp256k1
 import "C"
 
 import (
+	"errors"
 	"unsafe"
 )
 
+var (
+	ErrInvalidMsgLen       = errors.New("invalid message length, need 32 bytes")
+	ErrInvalidSignatureLen = errors.New("invalid signature length")
+	ErrInvalidRecoveryID   = errors.New("invalid signature recovery id")
+	ErrInvalidKey          = errors.New("invalid private key")
+	ErrSignFailed          = errors.New("signing failed")
+	ErrRecoverFailed       = errors.New("recovery failed")
+	ErrInvalidAuxRandLen   = errors.New("invalid auxiliary randomness length, need 32 bytes")
+)
+
 var context *C.secp256k1_context
 
 func init() {
@@ -174,6 +195,21 @@ func CheckLowS(sig []byte) bool {
 	return C.secp256k1_ecdsa_signature_normalize(context, nil, csig) == cInt(0)
 }
 
+// ParseDERLax parses the DER signature as leniently as consensus did before BIP66
+// and returns it in the 64-byte compact [R || S] format, R and S overflowing the
+// curve order are returned as zeros
+func ParseDERLax(sig []byte) ([]byte, bool) {
+	csig, ok := parseSignatureFromBytes(sig)
+	if !ok {
+		return nil, false
+	}
+
+	compact := make([]byte, 64)
+	C.secp256k1_ecdsa_signature_serialize_compact(context, cBuf(compact), csig)
+
+	return compact, true
+}
+
 func VerifySignature(pubkey, msg, sig []byte) bool {
 	csig, ok := parseSignatureFromBytes(sig)
 	if !ok {
@@ -243,6 +279,154 @@ func Signature(msg []byte, privatekey []byte, testCase uint32) ([]byte, bool) {
 	return C.GoBytes(unsafe.Pointer(&sigBuf[0]), cInt(nsig)), rv == C.int(1)
 }
 
+// VerifySeckey checks the secret key is a valid scalar, 0 < seckey < n
+func VerifySeckey(seckey []byte) bool {
+	if len(seckey) != 32 {
+		return false
+	}
+
+	return C.secp256k1_ec_seckey_verify(context, cBuf(seckey)) == cInt(1)
+}
+
+// Sign creates a recoverable ECDSA signature.
+// The produced signature is in the 65-byte [R || S || V] format where V is 0 or 1.
+func Sign(msg []byte, seckey []byte) ([]byte, error) {
+	if len(msg) != 32 {
+		return nil, ErrInvalidMsgLen
+	}
+
+	if !VerifySeckey(seckey) {
+		return nil, ErrInvalidKey
+	}
+
+	var (
+		noncefunc = C.secp256k1_nonce_function_rfc6979
+		sigstruct C.secp256k1_ecdsa_recoverable_signature
+	)
+
+	rv := C.secp256k1_ecdsa_sign_recoverable(
+		context,
+		&sigstruct,
+		cBuf(msg),
+		cBuf(seckey),
+		noncefunc,
+		nil,
+	)
+	if rv == cInt(0) {
+		return nil, ErrSignFailed
+	}
+
+	var (
+		sig   = make([]byte, 65)
+		recid C.int
+	)
+
+	C.secp256k1_ecdsa_recoverable_signature_serialize_compact(context, cBuf(sig), &recid, &sigstruct)
+	sig[64] = byte(recid)
+
+	return sig, nil
+}
+
+// RecoverPubkey returns the the public key of the signer.
+// msg must be the 32-byte hash of the message to be signed.
+// sig must be a 65-byte compact ECDSA signature containing the
+// recovery id as the last element.
+func RecoverPubkey(msg []byte, sig []byte) ([]byte, error) {
+	if len(msg) != 32 {
+		return nil, ErrInvalidMsgLen
+	}
+
+	if err := checkSignature(sig); err != nil {
+		return nil, err
+	}
+
+	pubkey := make([]byte, 65)
+	if C.secp256k1_ext_ecdsa_recover(context, cBuf(pubkey), cBuf(sig), cBuf(msg)) == cInt(0) {
+		return nil, ErrRecoverFailed
+	}
+
+	return pubkey, nil
+}
+
+func checkSignature(sig []byte) error {
+	if len(sig) != 65 {
+		return ErrInvalidSignatureLen
+	}
+
+	if sig[64] >= 4 {
+		return ErrInvalidRecoveryID
+	}
+
+	return nil
+}
+
+// SchnorrSign creates the 64-byte BIP340 signature of the 32-byte message.
+// auxRand should be 32 fresh random bytes, nil signs deterministically with zeros.
+func SchnorrSign(msg, seckey, auxRand []byte) ([]byte, error) {
+	if len(msg) != 32 {
+		return nil, ErrInvalidMsgLen
+	}
+
+	if !VerifySeckey(seckey) {
+		return nil, ErrInvalidKey
+	}
+
+	if auxRand == nil {
+		auxRand = make([]byte, 32)
+	}
+
+	if len(auxRand) != 32 {
+		return nil, ErrInvalidAuxRandLen
+	}
+
+	sig := make([]byte, 64)
+	if C.secp256k1_ext_schnorr_sign(context, cBuf(sig), cBuf(msg), cBuf(seckey), cBuf(auxRand)) == cInt(0) {
+		return nil, ErrSignFailed
+	}
+
+	return sig, nil
+}
+
+// SchnorrVerify checks the 64-byte BIP340 signature of the message against the 32-byte x-only public key
+func SchnorrVerify(pubkey, msg, sig []byte) bool {
+	if len(pubkey) != 32 || len(sig) != 64 {
+		return false
+	}
+
+	var cmsg *C.uchar
+	if len(msg) > 0 {
+		cmsg = cBuf(msg)
+	}
+
+	return C.secp256k1_ext_schnorr_verify(context, cBuf(sig), cmsg, C.size_t(len(msg)), cBuf(pubkey)) == cInt(1)
+}
+
+// NegateSeckey returns n - seckey, the secret key of the negated public key
+func NegateSeckey(seckey []byte) ([]byte, error) {
+	if len(seckey) != 32 {
+		return nil, ErrInvalidKey
+	}
+
+	negated := make([]byte, 32)
+	copy(negated, seckey)
+	if C.secp256k1_ext_seckey_negate(context, cBuf(negated)) == cInt(0) {
+		Zeroize(negated)
+		return nil, ErrInvalidKey
+	}
+
+	return negated, nil
+}
+
+// Zeroize overwrites the buffer with zeros, secret keys passed to and returned
+// by this package should be wiped with it once they are no longer needed
+func Zeroize(b []byte) {
+	if len(b) == 0 {
+		return
+	}
+
+	C.secp256k1_go_memclear(unsafe.Pointer(&b[0]), C.size_t(len(b)))
+}
+
 func CreatePubkeyFromBytes(privatekey []byte, compressed bool) ([]byte, bool) {
 	pubkey := &C.secp256k1_pubkey{}
 	success := C.secp256k1_ec_pubkey_create(
@@ -253,6 +437,113 @@ func CreatePubkeyFromBytes(privatekey []byte, compressed bool) ([]byte, bool) {
 		return nil, false
 	}
 
+	return serializePubkey(pubkey, compressed)
+}
+
+// TweakAddPubkey returns pubkey + tweak*G, serialized in the same format as pubkey
+func TweakAddPubkey(pubkey, tweak []byte) ([]byte, bool) {
+	if len(pubkey) == 0 || len(tweak) != 32 {
+		return nil, false
+	}
+
+	var cpubkey C.secp256k1_pubkey
+	rv := C.secp256k1_ec_pubkey_parse(
+		context,
+		&cpubkey,
+		cBuf(pubkey),
+		cUlong(uint(len(pubkey))),
+	)
+	if rv == cInt(0) {
+		return nil, false
+	}
+
+	if C.secp256k1_ec_pubkey_tweak_add(context, &cpubkey, cBuf(tweak)) != cInt(1) {
+		return nil, false
+	}
+
+	return serializePubkey(&cpubkey, len(pubkey) == 33)
+}
+
+// TweakAddSeckey returns seckey + tweak mod n
+func TweakAddSeckey(seckey, tweak []byte) ([]byte, bool) {
+	if len(seckey) != 32 || len(tweak) != 32 {
+		return nil, false
+	}
+
+	tweaked := make([]byte, 32)
+	copy(tweaked, seckey)
+	if C.secp256k1_ec_privkey_tweak_add(context, cBuf(tweaked), cBuf(tweak)) != cInt(1) {
+		Zeroize(tweaked)
+		return nil, false
+	}
+
+	return tweaked, true
+}
+
+// TweakMulPubkey returns tweak*pubkey, serialized in the same format as pubkey
+func TweakMulPubkey(pubkey, tweak []byte) ([]byte, bool) {
+	if len(pubkey) == 0 || len(tweak) != 32 {
+		return nil, false
+	}
+
+	var cpubkey C.secp256k1_pubkey
+	rv := C.secp256k1_ec_pubkey_parse(
+		context,
+		&cpubkey,
+		cBuf(pubkey),
+		cUlong(uint(len(pubkey))),
+	)
+	if rv == cInt(0) {
+		return nil, false
+	}
+
+	if C.secp256k1_ec_pubkey_tweak_mul(context, &cpubkey, cBuf(tweak)) != cInt(1) {
+		return nil, false
+	}
+
+	return serializePubkey(&cpubkey, len(pubkey) == 33)
+}
+
+// TweakMulSeckey returns seckey * tweak mod n
+func TweakMulSeckey(seckey, tweak []byte) ([]byte, bool) {
+	if len(seckey) != 32 || len(tweak) != 32 {
+		return nil, false
+	}
+
+	tweaked := make([]byte, 32)
+	copy(tweaked, seckey)
+	if C.secp256k1_ec_privkey_tweak_mul(context, cBuf(tweaked), cBuf(tweak)) != cInt(1) {
+		Zeroize(tweaked)
+		return nil, false
+	}
+
+	return tweaked, true
+}
+
+// ReencodePubkey parses the public key and serializes it compressed or uncompressed
+func ReencodePubkey(pubkey []byte, compressed bool) ([]byte, bool) {
+	if len(pubkey) == 0 {
+		return nil, false
+	}
+
+	outlen := 65
+	if compressed {
+		outlen = 33
+	}
+
+	out := make([]byte, outlen)
+	rv := C.secp256k1_ext_reencode_pubkey(
+		context,
+		cBuf(out),
+		C.size_t(outlen),
+		cBuf(pubkey),
+		C.size_t(len(pubkey)),
+	)
+
+	return out, rv == cInt(1)
+}
+
+func serializePubkey(pubkey *C.secp256k1_pubkey, compressed bool) ([]byte, bool) {
 	flags := uint(C.SECP256K1_EC_UNCOMPRESSED)
 	if compressed {
 		flags = C.SECP256K1_EC_COMPRESSED
@@ -261,7 +552,7 @@ func CreatePubkeyFromBytes(privatekey []byte, compressed bool) ([]byte, bool) {
 	buflen := 65
 	buf := make([]byte, buflen)
 
-	success = C.secp256k1_ec_pubkey_serialize(
+	success := C.secp256k1_ec_pubkey_serialize(
 		context,
 		cBuf(buf[:]),
 		(*C.ulong)(unsafe.Pointer(&buflen)),
@@ -269,7 +560,7 @@ func CreatePubkeyFromBytes(privatekey []byte, compressed bool) ([]byte, bool) {
 		(C.uint)(flags),
 	)
 
-	return C.GoBytes(unsafe.Pointer(&buf[0]), (C.int)(buflen)), success == C.int(1)
+	return buf[:buflen], success == C.int(1)
 }
 
 func parseSignatureFromBytes(data []byte) (*C.secp256k1_ecdsa_signature, bool) {
@@ -0,0 +1,164 @@
+package bcrypto
+
+import (
+	"errors"
+	"fmt"
+)
+
+// SigHashType is the hash type byte trailing a script signature, it selects
+// the parts of the spending transaction the signature commits to
+type SigHashType byte
+
+const (
+	// SigHashDefault commits like SigHashAll, it is only defined for Taproot
+	// where it is implied by a 64-byte signature
+	SigHashDefault SigHashType = 0x00
+	// SigHashAll commits to all inputs and outputs
+	SigHashAll SigHashType = 0x01
+	// SigHashNone commits to the inputs but no output
+	SigHashNone SigHashType = 0x02
+	// SigHashSingle commits to the inputs and the output of the same index
+	SigHashSingle SigHashType = 0x03
+	// SigHashAnyoneCanPay is combined with the others to commit to the signed input only
+	SigHashAnyoneCanPay SigHashType = 0x80
+)
+
+const (
+	schnorrSignatureSize = 64
+)
+
+var (
+	// ErrSigHashUndefined represents the hash type byte is not a defined hash type
+	ErrSigHashUndefined = errors.New("signature: undefined hash type")
+	// ErrScriptSignatureEmpty represents the script signature has no hash type byte
+	ErrScriptSignatureEmpty = errors.New("signature: empty script signature")
+	// ErrTaprootSignatureBadLength represents the Taproot signature is neither 64 nor 65 bytes
+	ErrTaprootSignatureBadLength = errors.New("signature: bad taproot signature length")
+)
+
+// Base returns the hash type without SigHashAnyoneCanPay
+func (t SigHashType) Base() SigHashType {
+	return t &^ SigHashAnyoneCanPay
+}
+
+// AnyoneCanPay reports whether SigHashAnyoneCanPay is set
+func (t SigHashType) AnyoneCanPay() bool {
+	return t&SigHashAnyoneCanPay != 0
+}
+
+// IsDefined reports whether the hash type is ALL, NONE or SINGLE, optionally
+// with ANYONECANPAY, as required of legacy and segwit v0 signatures
+func (t SigHashType) IsDefined() bool {
+	base := t.Base()
+	return base >= SigHashAll && base <= SigHashSingle
+}
+
+// IsDefinedTaproot reports whether the hash type is defined for Taproot,
+// which adds SigHashDefault
+// see https://github.com/bitcoin/bips/blob/master/bip-0341.mediawiki
+func (t SigHashType) IsDefinedTaproot() bool {
+	return t == SigHashDefault || t.IsDefined()
+}
+
+func (t SigHashType) String() string {
+	if t == SigHashDefault {
+		return "DEFAULT"
+	}
+
+	var base string
+	switch t.Base() {
+	case SigHashAll:
+		base = "ALL"
+	case SigHashNone:
+		base = "NONE"
+	case SigHashSingle:
+		base = "SINGLE"
+	}
+
+	if base == "" {
+		return fmt.Sprintf("0x%02x", byte(t))
+	}
+
+	if t.AnyoneCanPay() {
+		return base + "|ANYONECANPAY"
+	}
+
+	return base
+}
+
+// ScriptSignature is an ECDSA signature as pushed by scripts: DER || hash type
+type ScriptSignature struct {
+	Signature *Signature
+	HashType  SigHashType
+}
+
+// NewScriptSignature returns the script signature of the signature and hash type
+func NewScriptSignature(sig *Signature, hashType SigHashType) (*ScriptSignature, error) {
+	if !hashType.IsDefined() {
+		return nil, ErrSigHashUndefined
+	}
+
+	return &ScriptSignature{
+		Signature: sig,
+		HashType:  hashType,
+	}, nil
+}
+
+// ParseScriptSignature parses the DER signature with ParseDERStrict, as BIP66
+// requires, and the trailing hash type byte
+func ParseScriptSignature(data []byte) (*ScriptSignature, error) {
+	if len(data) == 0 {
+		return nil, ErrScriptSignatureEmpty
+	}
+
+	hashType := SigHashType(data[len(data)-1])
+	if !hashType.IsDefined() {
+		return nil, ErrSigHashUndefined
+	}
+
+	sig, err := ParseDERStrict(data[:len(data)-1])
+	if err != nil {
+		return nil, err
+	}
+
+	return &ScriptSignature{
+		Signature: sig,
+		HashType:  hashType,
+	}, nil
+}
+
+// Serialize returns the DER signature followed by the hash type byte
+func (ss *ScriptSignature) Serialize() []byte {
+	return append(ss.Signature.Serialize(), byte(ss.HashType))
+}
+
+// ParseTaprootSignature splits the Taproot signature into the 64-byte Schnorr
+// signature and the hash type, which is SigHashDefault when omitted and can't
+// be given explicitly
+func ParseTaprootSignature(data []byte) ([]byte, SigHashType, error) {
+	switch len(data) {
+	case schnorrSignatureSize:
+		return data, SigHashDefault, nil
+	case schnorrSignatureSize + 1:
+		// an explicit SigHashDefault is not defined
+		hashType := SigHashType(data[schnorrSignatureSize])
+		if !hashType.IsDefined() {
+			return nil, 0, ErrSigHashUndefined
+		}
+
+		return data[:schnorrSignatureSize], hashType, nil
+	}
+
+	return nil, 0, ErrTaprootSignatureBadLength
+}
+
+// SerializeTaprootSignature appends the hash type to the 64-byte Schnorr signature,
+// SigHashDefault is left implicit
+func SerializeTaprootSignature(sig []byte, hashType SigHashType) []byte {
+	data := append([]byte{}, sig...)
+	if hashType == SigHashDefault {
+		return data
+	}
+
+	return append(data, byte(hashType))
+}
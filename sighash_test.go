@@ -0,0 +1,144 @@
+package bcrypto
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+)
+
+func TestSigHashType(t *testing.T) {
+	tests := []struct {
+		hashType SigHashType
+		defined  bool
+		taproot  bool
+		str      string
+	}{
+		{SigHashDefault, false, true, "DEFAULT"},
+		{SigHashAll, true, true, "ALL"},
+		{SigHashNone, true, true, "NONE"},
+		{SigHashSingle, true, true, "SINGLE"},
+		{SigHashAll | SigHashAnyoneCanPay, true, true, "ALL|ANYONECANPAY"},
+		{SigHashNone | SigHashAnyoneCanPay, true, true, "NONE|ANYONECANPAY"},
+		{SigHashSingle | SigHashAnyoneCanPay, true, true, "SINGLE|ANYONECANPAY"},
+		{SigHashAnyoneCanPay, false, false, "0x80"},
+		{0x04, false, false, "0x04"},
+		{0x41, false, false, "0x41"},
+		{0x84, false, false, "0x84"},
+	}
+
+	for _, test := range tests {
+		if test.hashType.IsDefined() != test.defined || test.hashType.IsDefinedTaproot() != test.taproot {
+			t.Errorf("%s: expect defined %v taproot %v", test.str, test.defined, test.taproot)
+		}
+
+		if test.hashType.String() != test.str {
+			t.Errorf("expect %s got %s", test.str, test.hashType)
+		}
+	}
+}
+
+func TestScriptSignature(t *testing.T) {
+	pk, err := NewPrivateKeyFromWIF("KwDiBf89QgGbjEhKnhXJuH7LrciVrZi3qYjgd9M7rFU73sVHnoWn")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pubkey, err := pk.PublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hash := sha256.Sum256([]byte("script signature"))
+	der, err := pk.SignHash(hash[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, hashType := range []SigHashType{SigHashAll, SigHashNone | SigHashAnyoneCanPay, SigHashSingle | SigHashAnyoneCanPay} {
+		data := append(append([]byte{}, der...), byte(hashType))
+
+		ss, err := ParseScriptSignature(data)
+		if err != nil {
+			t.Fatalf("%s: %v", hashType, err)
+		}
+
+		if ss.HashType != hashType || !bytes.Equal(ss.Serialize(), data) {
+			t.Errorf("expect %s %x got %s %x", hashType, data, ss.HashType, ss.Serialize())
+		}
+
+		if !pubkey.Verify(hash[:], ss.Signature.Serialize()) {
+			t.Errorf("%s: expect signature valid", hashType)
+		}
+	}
+
+	for _, hashType := range []SigHashType{SigHashDefault, SigHashAnyoneCanPay, 0x04, 0x84} {
+		if _, err := ParseScriptSignature(append(append([]byte{}, der...), byte(hashType))); err != ErrSigHashUndefined {
+			t.Errorf("%s: expect ErrSigHashUndefined got %v", hashType, err)
+		}
+	}
+
+	if _, err := ParseScriptSignature(nil); err != ErrScriptSignatureEmpty {
+		t.Errorf("expect ErrScriptSignatureEmpty got %v", err)
+	}
+
+	// the DER must be strict, here R has excessive padding
+	padded, _ := hex.DecodeString("30070202000102010101")
+	if _, err := ParseScriptSignature(padded); err != ErrSignatureRPadding {
+		t.Errorf("expect ErrSignatureRPadding got %v", err)
+	}
+
+	sig, err := ParseDERStrict(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewScriptSignature(sig, SigHashDefault); err != ErrSigHashUndefined {
+		t.Errorf("expect ErrSigHashUndefined got %v", err)
+	}
+
+	ss, err := NewScriptSignature(sig, SigHashAll|SigHashAnyoneCanPay)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expect := append(append([]byte{}, der...), 0x81); !bytes.Equal(ss.Serialize(), expect) {
+		t.Errorf("expect %x got %x", expect, ss.Serialize())
+	}
+}
+
+func TestTaprootSignature(t *testing.T) {
+	sig := bytes.Repeat([]byte{0x11}, 64)
+
+	for _, hashType := range []SigHashType{SigHashDefault, SigHashAll, SigHashSingle | SigHashAnyoneCanPay} {
+		data := SerializeTaprootSignature(sig, hashType)
+
+		expectLen := 65
+		if hashType == SigHashDefault {
+			expectLen = 64
+		}
+
+		if len(data) != expectLen {
+			t.Errorf("%s: expect %d bytes got %d", hashType, expectLen, len(data))
+		}
+
+		parsed, parsedType, err := ParseTaprootSignature(data)
+		if err != nil {
+			t.Fatalf("%s: %v", hashType, err)
+		}
+
+		if parsedType != hashType || !bytes.Equal(parsed, sig) {
+			t.Errorf("expect %s %x got %s %x", hashType, sig, parsedType, parsed)
+		}
+	}
+
+	for _, hashType := range []SigHashType{SigHashDefault, SigHashAnyoneCanPay, 0x04} {
+		if _, _, err := ParseTaprootSignature(append(append([]byte{}, sig...), byte(hashType))); err != ErrSigHashUndefined {
+			t.Errorf("%s: expect ErrSigHashUndefined got %v", hashType, err)
+		}
+	}
+
+	if _, _, err := ParseTaprootSignature(sig[:63]); err != ErrTaprootSignatureBadLength {
+		t.Errorf("expect ErrTaprootSignatureBadLength got %v", err)
+	}
+}
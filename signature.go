@@ -1,7 +1,323 @@
 package bcrypto
 
-import "github.com/detailyang/go-bcrypto/secp256k1"
+import (
+	"errors"
+	"math/big"
 
+	"github.com/detailyang/go-bcrypto/secp256k1"
+)
+
+const (
+	// minDERSignatureSize and maxDERSignatureSize bound the DER signature without
+	// the hash type byte, BIP66 counts it in its 9 and 73 bytes
+	minDERSignatureSize = 8
+	maxDERSignatureSize = 72
+
+	// CompactSignatureSize is the length of the [R || S] signature
+	CompactSignatureSize = 64
+)
+
+var (
+	// ErrSignatureTooShort represents the DER signature is shorter than 8 bytes
+	ErrSignatureTooShort = errors.New("signature: DER too short")
+	// ErrSignatureTooLong represents the DER signature is longer than 72 bytes
+	ErrSignatureTooLong = errors.New("signature: DER too long")
+	// ErrSignatureBadSequence represents the DER signature is not a compound structure
+	ErrSignatureBadSequence = errors.New("signature: DER is not a sequence")
+	// ErrSignatureBadLength represents the DER lengths do not cover the signature exactly
+	ErrSignatureBadLength = errors.New("signature: DER bad length")
+	// ErrSignatureBadRMarker represents R is not a DER integer
+	ErrSignatureBadRMarker = errors.New("signature: R is not an integer")
+	// ErrSignatureZeroRLength represents R has zero length
+	ErrSignatureZeroRLength = errors.New("signature: R has zero length")
+	// ErrSignatureNegativeR represents R has the sign bit set
+	ErrSignatureNegativeR = errors.New("signature: R is negative")
+	// ErrSignatureRPadding represents R has a null byte not needed for the sign
+	ErrSignatureRPadding = errors.New("signature: R has excessive padding")
+	// ErrSignatureBadSMarker represents S is not a DER integer
+	ErrSignatureBadSMarker = errors.New("signature: S is not an integer")
+	// ErrSignatureZeroSLength represents S has zero length
+	ErrSignatureZeroSLength = errors.New("signature: S has zero length")
+	// ErrSignatureNegativeS represents S has the sign bit set
+	ErrSignatureNegativeS = errors.New("signature: S is negative")
+	// ErrSignatureSPadding represents S has a null byte not needed for the sign
+	ErrSignatureSPadding = errors.New("signature: S has excessive padding")
+	// ErrSignatureRRange represents R is not in [1, n-1]
+	ErrSignatureRRange = errors.New("signature: R out of range")
+	// ErrSignatureSRange represents S is not in [1, n-1]
+	ErrSignatureSRange = errors.New("signature: S out of range")
+	// ErrSignatureBadDER represents the signature can't be parsed even leniently
+	ErrSignatureBadDER = errors.New("signature: bad DER")
+	// ErrSignatureBadCompact represents the compact signature is not 64 bytes
+	ErrSignatureBadCompact = errors.New("signature: bad compact length")
+)
+
+var (
+	curveOrder     = secp256k1.S256().Params().N
+	curveHalfOrder = new(big.Int).Rsh(curveOrder, 1)
+)
+
+// Signature is an ECDSA signature whose R and S are in [1, n-1]
+type Signature struct {
+	r [32]byte
+	s [32]byte
+}
+
+// NewSignature returns the signature of R and S
+func NewSignature(r, s *big.Int) (*Signature, error) {
+	if !inCurveOrder(r) {
+		return nil, ErrSignatureRRange
+	}
+
+	if !inCurveOrder(s) {
+		return nil, ErrSignatureSRange
+	}
+
+	sig := &Signature{}
+	r.FillBytes(sig.r[:])
+	s.FillBytes(sig.s[:])
+	return sig, nil
+}
+
+// ParseDERStrict parses the DER signature enforcing the encoding rules of BIP66,
+// the signature must not carry the hash type byte
+// see https://github.com/bitcoin/bips/blob/master/bip-0066.mediawiki
+func ParseDERStrict(der []byte) (*Signature, error) {
+	n := len(der)
+
+	if n < minDERSignatureSize {
+		return nil, ErrSignatureTooShort
+	}
+
+	if n > maxDERSignatureSize {
+		return nil, ErrSignatureTooLong
+	}
+
+	// 0x30 [total-length] 0x02 [R-length] [R] 0x02 [S-length] [S]
+	if der[0] != 0x30 {
+		return nil, ErrSignatureBadSequence
+	}
+
+	if int(der[1]) != n-2 {
+		return nil, ErrSignatureBadLength
+	}
+
+	rlen := int(der[3])
+	if 5+rlen >= n {
+		return nil, ErrSignatureBadLength
+	}
+
+	slen := int(der[5+rlen])
+	if rlen+slen+6 != n {
+		return nil, ErrSignatureBadLength
+	}
+
+	if der[2] != 0x02 {
+		return nil, ErrSignatureBadRMarker
+	}
+
+	if rlen == 0 {
+		return nil, ErrSignatureZeroRLength
+	}
+
+	if der[4]&0x80 != 0 {
+		return nil, ErrSignatureNegativeR
+	}
+
+	if rlen > 1 && der[4] == 0 && der[5]&0x80 == 0 {
+		return nil, ErrSignatureRPadding
+	}
+
+	if der[rlen+4] != 0x02 {
+		return nil, ErrSignatureBadSMarker
+	}
+
+	if slen == 0 {
+		return nil, ErrSignatureZeroSLength
+	}
+
+	if der[rlen+6]&0x80 != 0 {
+		return nil, ErrSignatureNegativeS
+	}
+
+	if slen > 1 && der[rlen+6] == 0 && der[rlen+7]&0x80 == 0 {
+		return nil, ErrSignatureSPadding
+	}
+
+	r := new(big.Int).SetBytes(der[4 : 4+rlen])
+	s := new(big.Int).SetBytes(der[6+rlen:])
+	return NewSignature(r, s)
+}
+
+// ParseDERLax parses the DER signature as leniently as consensus did before BIP66
+func ParseDERLax(der []byte) (*Signature, error) {
+	// the lax parser of libsecp256k1 zeroes both R and S when either overflows,
+	// so they are checked here to report which one is out of range
+	r, s, ok := laxDERIntegers(der)
+	if !ok {
+		return nil, ErrSignatureBadDER
+	}
+
+	if !inCurveOrder(new(big.Int).SetBytes(r)) {
+		return nil, ErrSignatureRRange
+	}
+
+	if !inCurveOrder(new(big.Int).SetBytes(s)) {
+		return nil, ErrSignatureSRange
+	}
+
+	compact, ok := secp256k1.ParseDERLax(der)
+	if !ok {
+		return nil, ErrSignatureBadDER
+	}
+
+	return ParseCompact(compact)
+}
+
+// ParseCompact parses the 64-byte [R || S] signature
+func ParseCompact(compact []byte) (*Signature, error) {
+	if len(compact) != CompactSignatureSize {
+		return nil, ErrSignatureBadCompact
+	}
+
+	r := new(big.Int).SetBytes(compact[:32])
+	s := new(big.Int).SetBytes(compact[32:])
+	return NewSignature(r, s)
+}
+
+// R returns a copy of R
+func (sig *Signature) R() *big.Int {
+	return new(big.Int).SetBytes(sig.r[:])
+}
+
+// S returns a copy of S
+func (sig *Signature) S() *big.Int {
+	return new(big.Int).SetBytes(sig.s[:])
+}
+
+// Serialize returns the shortest DER encoding, which ParseDERStrict accepts
+func (sig *Signature) Serialize() []byte {
+	r := derInteger(sig.r[:])
+	s := derInteger(sig.s[:])
+
+	der := make([]byte, 0, 6+len(r)+len(s))
+	der = append(der, 0x30, byte(4+len(r)+len(s)), 0x02, byte(len(r)))
+	der = append(der, r...)
+	der = append(der, 0x02, byte(len(s)))
+	return append(der, s...)
+}
+
+// SerializeCompact returns the 64-byte [R || S] signature
+func (sig *Signature) SerializeCompact() []byte {
+	compact := make([]byte, 0, CompactSignatureSize)
+	compact = append(compact, sig.r[:]...)
+	return append(compact, sig.s[:]...)
+}
+
+// IsLowS reports whether S is at most n/2, as standardness requires
+// see https://github.com/bitcoin/bips/blob/master/bip-0146.mediawiki
+func (sig *Signature) IsLowS() bool {
+	return sig.S().Cmp(curveHalfOrder) <= 0
+}
+
+// Normalize returns the signature with S replaced by n - S if S is high,
+// both verify against the same key
+func (sig *Signature) Normalize() *Signature {
+	normalized := *sig
+	if !sig.IsLowS() {
+		new(big.Int).Sub(curveOrder, sig.S()).FillBytes(normalized.s[:])
+	}
+
+	return &normalized
+}
+
+// CheckLowS reports whether the leniently parsed DER signature has a low S
 func CheckLowS(sig []byte) bool {
 	return secp256k1.CheckLowS(sig)
 }
+
+// derInteger strips the leading zeros of the unsigned big-endian value and
+// prepends one when the sign bit would be set
+func derInteger(v []byte) []byte {
+	for len(v) > 1 && v[0] == 0 {
+		v = v[1:]
+	}
+
+	if v[0]&0x80 != 0 {
+		return append([]byte{0}, v...)
+	}
+
+	return v
+}
+
+// laxDERIntegers walks the signature like the lax DER parser of libsecp256k1
+// and returns R and S
+func laxDERIntegers(der []byte) ([]byte, []byte, bool) {
+	if len(der) < 2 || der[0] != 0x30 {
+		return nil, nil, false
+	}
+
+	// the sequence length is skipped, not checked
+	pos := 2
+	if lenbyte := int(der[1]); lenbyte&0x80 != 0 {
+		pos += lenbyte - 0x80
+		if pos > len(der) {
+			return nil, nil, false
+		}
+	}
+
+	r, pos, ok := laxDERInteger(der, pos)
+	if !ok {
+		return nil, nil, false
+	}
+
+	s, _, ok := laxDERInteger(der, pos)
+	if !ok {
+		return nil, nil, false
+	}
+
+	return r, s, true
+}
+
+// laxDERInteger reads the integer at pos, which may have a long form length
+// and any number of leading zeros, and returns the position following it
+func laxDERInteger(der []byte, pos int) ([]byte, int, bool) {
+	if pos+2 > len(der) || der[pos] != 0x02 {
+		return nil, 0, false
+	}
+
+	n := int(der[pos+1])
+	pos += 2
+
+	if n&0x80 != 0 {
+		lenbyte := n - 0x80
+		if pos+lenbyte > len(der) {
+			return nil, 0, false
+		}
+
+		for lenbyte > 0 && der[pos] == 0 {
+			pos++
+			lenbyte--
+		}
+
+		if lenbyte >= 8 {
+			return nil, 0, false
+		}
+
+		n = 0
+		for ; lenbyte > 0; lenbyte-- {
+			n = n<<8 + int(der[pos])
+			pos++
+		}
+	}
+
+	if n > len(der)-pos {
+		return nil, 0, false
+	}
+
+	return der[pos : pos+n], pos + n, true
+}
+
+func inCurveOrder(v *big.Int) bool {
+	return v.Sign() > 0 && v.Cmp(curveOrder) < 0
+}
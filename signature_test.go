@@ -0,0 +1,163 @@
+package bcrypto
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"math/big"
+	"testing"
+)
+
+const curveOrderHex = "fffffffffffffffffffffffffffffffebaaedce6af48a03bbfd25e8cd0364141"
+
+func TestSignature(t *testing.T) {
+	pk, err := NewPrivateKeyFromWIF("KwDiBf89QgGbjEhKnhXJuH7LrciVrZi3qYjgd9M7rFU73sVHnoWn")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pubkey, err := pk.PublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hash := sha256.Sum256([]byte("signature"))
+	der, err := pk.SignHash(hash[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sig, err := ParseDERStrict(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(sig.Serialize(), der) {
+		t.Errorf("expect %x got %x", der, sig.Serialize())
+	}
+
+	if !sig.IsLowS() || !CheckLowS(der) {
+		t.Error("expect low S")
+	}
+
+	compact, err := ParseCompact(sig.SerializeCompact())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if compact.R().Cmp(sig.R()) != 0 || compact.S().Cmp(sig.S()) != 0 {
+		t.Errorf("expect %x %x got %x %x", sig.R(), sig.S(), compact.R(), compact.S())
+	}
+
+	n, _ := new(big.Int).SetString(curveOrderHex, 16)
+	high, err := NewSignature(sig.R(), new(big.Int).Sub(n, sig.S()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if high.IsLowS() || CheckLowS(high.Serialize()) {
+		t.Error("expect high S")
+	}
+
+	if _, err := ParseDERStrict(high.Serialize()); err != nil {
+		t.Errorf("expect high S strict DER got %v", err)
+	}
+
+	if !pubkey.Verify(hash[:], high.Serialize()) {
+		t.Error("expect high S signature valid")
+	}
+
+	if normalized := high.Normalize(); !bytes.Equal(normalized.Serialize(), der) || high.IsLowS() {
+		t.Errorf("expect %x got %x", der, normalized.Serialize())
+	}
+}
+
+func TestParseDERStrict(t *testing.T) {
+	n := "022100" + curveOrderHex
+
+	tests := []struct {
+		der string
+		err error
+	}{
+		{"3006020101020101", nil},
+		{"30060201010201", ErrSignatureTooShort},
+		{"3046022100" + curveOrderHex + "022100" + curveOrderHex + "00", ErrSignatureTooLong},
+		{"3106020101020101", ErrSignatureBadSequence},
+		{"3007020101020101", ErrSignatureBadLength},
+		{"3006020401020101", ErrSignatureBadLength},
+		{"3006020101020201", ErrSignatureBadLength},
+		{"3006030101020101", ErrSignatureBadRMarker},
+		{"3006020002020101", ErrSignatureZeroRLength},
+		{"3006020181020101", ErrSignatureNegativeR},
+		{"300702020001020101", ErrSignatureRPadding},
+		{"3006020101030101", ErrSignatureBadSMarker},
+		{"3006020201010200", ErrSignatureZeroSLength},
+		{"3006020101020181", ErrSignatureNegativeS},
+		{"300702010102020001", ErrSignatureSPadding},
+		{"3006020100020101", ErrSignatureRRange},
+		{"3026020101" + n, ErrSignatureSRange},
+	}
+
+	for _, test := range tests {
+		der, _ := hex.DecodeString(test.der)
+		if _, err := ParseDERStrict(der); err != test.err {
+			t.Errorf("%s: expect %v got %v", test.der, test.err, err)
+		}
+	}
+}
+
+func TestParseDERLax(t *testing.T) {
+	// padded R and S, and a wrong outer length are accepted before BIP66
+	for _, str := range []string{"300702020001020101", "300802020001020200013000", "3080020101020101"} {
+		der, _ := hex.DecodeString(str)
+		sig, err := ParseDERLax(der)
+		if err != nil {
+			t.Errorf("%s: %v", str, err)
+			continue
+		}
+
+		if hex.EncodeToString(sig.Serialize()) != "3006020101020101" {
+			t.Errorf("%s: expect 3006020101020101 got %x", str, sig.Serialize())
+		}
+	}
+
+	der, _ := hex.DecodeString("3106020101020101")
+	if _, err := ParseDERLax(der); err != ErrSignatureBadDER {
+		t.Errorf("expect ErrSignatureBadDER got %v", err)
+	}
+
+	tests := []struct {
+		der string
+		err error
+	}{
+		{"3026022100" + curveOrderHex + "020101", ErrSignatureRRange},
+		{"3026020101022100" + curveOrderHex, ErrSignatureSRange},
+		// long form lengths and extra leading zeros
+		{"30810702810200010200", ErrSignatureSRange},
+		{"3006020100020101", ErrSignatureRRange},
+	}
+
+	for _, test := range tests {
+		der, _ := hex.DecodeString(test.der)
+		if _, err := ParseDERLax(der); err != test.err {
+			t.Errorf("%s: expect %v got %v", test.der, test.err, err)
+		}
+	}
+}
+
+func TestParseCompact(t *testing.T) {
+	if _, err := ParseCompact(make([]byte, 63)); err != ErrSignatureBadCompact {
+		t.Errorf("expect ErrSignatureBadCompact got %v", err)
+	}
+
+	one := append(make([]byte, 31), 1)
+	n, _ := hex.DecodeString(curveOrderHex)
+
+	if _, err := ParseCompact(append(append([]byte{}, n...), one...)); err != ErrSignatureRRange {
+		t.Errorf("expect ErrSignatureRRange got %v", err)
+	}
+
+	if _, err := ParseCompact(append(append([]byte{}, one...), make([]byte, 32)...)); err != ErrSignatureSRange {
+		t.Errorf("expect ErrSignatureSRange got %v", err)
+	}
+}
@@ -0,0 +1,132 @@
+package bcrypto
+
+import (
+	"errors"
+)
+
+// ScriptType is the output script an extended key derives, implied by its SLIP-132 version
+// see https://github.com/satoshilabs/slips/blob/master/slip-0132.md
+type ScriptType int
+
+const (
+	// ScriptP2PKH is the script type of xpub/tpub keys, which are also used for legacy P2SH
+	ScriptP2PKH ScriptType = iota
+	// ScriptP2SHP2WPKH is the script type of ypub/upub keys
+	ScriptP2SHP2WPKH
+	// ScriptP2WPKH is the script type of zpub/vpub keys
+	ScriptP2WPKH
+	// ScriptP2SHP2WSH is the multisig script type of Ypub/Upub keys
+	ScriptP2SHP2WSH
+	// ScriptP2WSH is the multisig script type of Zpub/Vpub keys
+	ScriptP2WSH
+)
+
+var (
+	// ErrHDUnknownVersion represents the network has no extended key version for the script type
+	ErrHDUnknownVersion = errors.New("hdkey: unknown version")
+	// ErrHDMultisigScript represents the script type of the key has no single key address
+	ErrHDMultisigScript = errors.New("hdkey: multisig script type")
+)
+
+// HDVersion is the pair of extended key versions of a script type
+type HDVersion struct {
+	ScriptType   ScriptType
+	PrivateKeyID [4]byte
+	PublicKeyID  [4]byte
+}
+
+var (
+	mainnetHDVersions = []HDVersion{
+		{ScriptP2SHP2WPKH, [4]byte{0x04, 0x9d, 0x78, 0x78}, [4]byte{0x04, 0x9d, 0x7c, 0xb2}},
+		{ScriptP2WPKH, [4]byte{0x04, 0xb2, 0x43, 0x0c}, [4]byte{0x04, 0xb2, 0x47, 0x46}},
+		{ScriptP2SHP2WSH, [4]byte{0x02, 0x95, 0xb0, 0x05}, [4]byte{0x02, 0x95, 0xb4, 0x3f}},
+		{ScriptP2WSH, [4]byte{0x02, 0xaa, 0x7a, 0x99}, [4]byte{0x02, 0xaa, 0x7e, 0xd3}},
+	}
+
+	testnetHDVersions = []HDVersion{
+		{ScriptP2SHP2WPKH, [4]byte{0x04, 0x4a, 0x4e, 0x28}, [4]byte{0x04, 0x4a, 0x52, 0x62}},
+		{ScriptP2WPKH, [4]byte{0x04, 0x5f, 0x18, 0xbc}, [4]byte{0x04, 0x5f, 0x1c, 0xf6}},
+		{ScriptP2SHP2WSH, [4]byte{0x02, 0x42, 0x85, 0xb5}, [4]byte{0x02, 0x42, 0x89, 0xef}},
+		{ScriptP2WSH, [4]byte{0x02, 0x57, 0x50, 0x48}, [4]byte{0x02, 0x57, 0x54, 0x83}},
+	}
+)
+
+// HDVersionOf returns the extended key versions of the script type on the network,
+// ScriptP2PKH uses the BIP32 versions of the network
+func HDVersionOf(network Network, script ScriptType) (HDVersion, error) {
+	params, err := network.Params()
+	if err != nil {
+		return HDVersion{}, err
+	}
+
+	if script == ScriptP2PKH {
+		return HDVersion{ScriptP2PKH, params.HDPrivateKeyID, params.HDPublicKeyID}, nil
+	}
+
+	for _, version := range params.HDVersions {
+		if version.ScriptType == script {
+			return version, nil
+		}
+	}
+
+	return HDVersion{}, ErrHDUnknownVersion
+}
+
+// AddressType returns the address kind of the single key script types
+func (s ScriptType) AddressType() (AddressType, error) {
+	switch s {
+	case ScriptP2PKH:
+		return AddressP2PKH, nil
+	case ScriptP2SHP2WPKH:
+		return AddressP2SH, nil
+	case ScriptP2WPKH:
+		return AddressP2WPKH, nil
+	case ScriptP2SHP2WSH, ScriptP2WSH:
+		return 0, ErrHDMultisigScript
+	}
+
+	return 0, ErrHDUnknownVersion
+}
+
+func (s ScriptType) String() string {
+	switch s {
+	case ScriptP2PKH:
+		return "p2pkh"
+	case ScriptP2SHP2WPKH:
+		return "p2sh-p2wpkh"
+	case ScriptP2WPKH:
+		return "p2wpkh"
+	case ScriptP2SHP2WSH:
+		return "p2sh-p2wsh"
+	case ScriptP2WSH:
+		return "p2wsh"
+	}
+
+	return "unknown"
+}
+
+// WithScriptType returns a copy of the key serialized with the version of the script type
+func (k *ExtendedKey) WithScriptType(script ScriptType) (*ExtendedKey, error) {
+	if _, err := HDVersionOf(k.Network, script); err != nil {
+		return nil, err
+	}
+
+	key := *k
+	key.ScriptType = script
+	return &key, nil
+}
+
+// ConvertExtendedKey re-encodes the extended key with the version of the script type,
+// e.g. from zpub to xpub
+func ConvertExtendedKey(str string, script ScriptType) (string, error) {
+	k, err := ParseExtendedKey(str)
+	if err != nil {
+		return "", err
+	}
+
+	if k, err = k.WithScriptType(script); err != nil {
+		return "", err
+	}
+
+	return k.Encode()
+}
@@ -0,0 +1,153 @@
+package bcrypto
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+func TestSLIP132(t *testing.T) {
+	// seed of the mnemonic "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+	seed, _ := hex.DecodeString("5eb00bbddcf069084889a8ab9155568165f5c453ccb85e70811aaed6f6da5fc19a5ac40b389cd370d086206dec8aa6c43daea6690f20ad3d8d48b2d2ce9e38e4")
+
+	tests := []struct {
+		network Network
+		path    string
+		script  ScriptType
+		pub     string
+		prv     string
+	}{
+		{
+			Mainet, "m/84'/0'/0'", ScriptP2WPKH,
+			"zpub6rFR7y4Q2AijBEqTUquhVz398htDFrtymD9xYYfG1m4wAcvPhXNfE3EfH1r1ADqtfSdVCToUG868RvUUkgDKf31mGDtKsAYz2oz2AGutZYs",
+			"zprvAdG4iTXWBoARxkkzNpNh8r6Qag3irQB8PzEMkAFeTRXxHpbF9z4QgEvBRmfvqWvGp42t42nvgGpNgYSJA9iefm1yYNZKEm7z6qUWCroSQnE",
+		},
+		{
+			Mainet, "m/84'/0'/0'", ScriptP2PKH,
+			"xpub6CatWdiZiodmUeTDp8LT5or8nmbKNcuyvz7WyksVFkKB4RHwCD3XyuvPEbvqAQY3rAPshWcMLoP2fMFMKHPJ4ZeZXYVUhLv1VMrjPC7PW6V",
+			"xprv9ybY78BftS5UGANki6oSifuQEjkpyAC8ZmBvBNTshQnCBcxnefjHS7buPMkkqhcRzmoGZ5bokx7GuyDAiktd5HemohAU4wV1ZPMDRmLpBMm",
+		},
+		{
+			Mainet, "m/49'/0'/0'", ScriptP2SHP2WPKH,
+			"ypub6Ww3ibxVfGzLrAH1PNcjyAWenMTbbAosGNB6VvmSEgytSER9azLDWCxoJwW7Ke7icmizBMXrzBx9979FfaHxHcrArf3zbeJJJUZPf663zsP",
+			"yprvAHwhK6RbpuS3dgCYHM5jc2ZvEKd7Bi61u9FVhYMpgMSuZS613T1xxQeKTffhrHY79hZ5PsskBjcc6C2V7DrnsMsNaGDaWev3GLRQRgV7hxF",
+		},
+		{
+			Mainet, "m/48'/0'/0'/2'", ScriptP2WSH,
+			"Zpub74Jru6aftwwHxCUCWEvP6DgrfFsdA4U6ZRtQ5i8qJpMcC39yZGv3egBhQfV3MS9pZtH5z8iV5qWkJsK6ESs6mSzt4qvGhzJxPeeVS2e1zUG",
+			"ZprvAqKWVb3n4aNzjiPjQDPNj5k87E38kbkFCCxoHKjDkUpdKEpq1jbo6ssDZS4WgM43mLCQ6Gx6LM4DD5sigBvnjEPqouRuC7HbNeVagXvJcnz",
+		},
+		{
+			Testnet, "m/84'/1'/0'", ScriptP2WPKH,
+			"vpub5Y6cjg78GGuNLsaPhmYsiw4gYX3HoQiRBiSwDaBXKUafCt9bNwWQiitDk5VZ5BVxYnQdwoTyXSs2JHRPAgjAvtbBrf8ZhDYe2jWAqvZVnsc",
+			"vprv9K7GLAaERuM58PVvbk1sMo7wzVCoPwzZpVXLRBmum93gL5pSqQCAAvZjtmz93nnnYMr9i2FwG2fqrwYLRgJmDDwFjGiamGsbRMJ5Y6siJ8H",
+		},
+	}
+
+	for _, test := range tests {
+		master, err := NewMasterKey(test.network, seed)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		path, err := ParseDerivationPath(test.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		account, err := master.Derive(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if account, err = account.WithScriptType(test.script); err != nil {
+			t.Fatal(err)
+		}
+
+		pub, err := account.Neuter()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if prv, err := account.Encode(); err != nil || prv != test.prv {
+			t.Errorf("expect %s got %s %v", test.prv, prv, err)
+		}
+
+		if pub.String() != test.pub {
+			t.Errorf("expect %s got %s", test.pub, pub)
+		}
+
+		for _, str := range []string{test.prv, test.pub} {
+			k, err := ParseExtendedKey(str)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if k.Network != test.network || k.ScriptType != test.script {
+				t.Errorf("%s: expect %s %s got %s %s", str, test.network, test.script, k.Network, k.ScriptType)
+			}
+
+			if encoded, err := k.Encode(); err != nil || encoded != str {
+				t.Errorf("expect %s got %s %v", str, encoded, err)
+			}
+		}
+	}
+
+	xpub, err := ConvertExtendedKey(tests[0].pub, ScriptP2PKH)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if xpub != tests[1].pub {
+		t.Errorf("expect %s got %s", tests[1].pub, xpub)
+	}
+
+	zprv, err := ConvertExtendedKey(tests[1].prv, ScriptP2WPKH)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if zprv != tests[0].prv {
+		t.Errorf("expect %s got %s", tests[0].prv, zprv)
+	}
+}
+
+func TestSLIP132Address(t *testing.T) {
+	zpub := "zpub6rFR7y4Q2AijBEqTUquhVz398htDFrtymD9xYYfG1m4wAcvPhXNfE3EfH1r1ADqtfSdVCToUG868RvUUkgDKf31mGDtKsAYz2oz2AGutZYs"
+
+	k, err := ParseExtendedKey(zpub)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	kind, err := k.ScriptType.AddressType()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	child, err := k.Derive(DerivationPath{0, 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	address, err := child.Address(kind)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if address.String() != "bc1qcr8te4kr609gcawutmrza0j4xv80jy8z306fyu" {
+		t.Errorf("unexpected address %s", address)
+	}
+
+	if _, err := ScriptP2WSH.AddressType(); err != ErrHDMultisigScript {
+		t.Errorf("expect ErrHDMultisigScript got %v", err)
+	}
+
+	litecoin := registerLitecoin(t)
+	if _, err := HDVersionOf(litecoin, ScriptP2WPKH); err != ErrHDUnknownVersion {
+		t.Errorf("expect ErrHDUnknownVersion got %v", err)
+	}
+
+	if _, err := k.WithScriptType(ScriptType(42)); err != ErrHDUnknownVersion {
+		t.Errorf("expect ErrHDUnknownVersion got %v", err)
+	}
+}
@@ -0,0 +1,104 @@
+package bcrypto
+
+import (
+	"crypto/sha256"
+	"errors"
+
+	"github.com/detailyang/go-bcrypto/secp256k1"
+)
+
+var (
+	// ErrTaprootBadKey represents the key can not be used as a taproot internal key
+	ErrTaprootBadKey = errors.New("bad taproot key")
+	// ErrTaprootBadMerkleRoot represents the merkle root is neither empty nor 32 bytes
+	ErrTaprootBadMerkleRoot = errors.New("bad taproot merkle root")
+	// ErrTaprootBadTweak represents the tweak is not a valid scalar or cancels the key
+	ErrTaprootBadTweak = errors.New("bad taproot tweak")
+)
+
+// TaggedHash implements SHA256(SHA256(tag) || SHA256(tag) || msg)
+// see https://github.com/bitcoin/bips/blob/master/bip-0340.mediawiki#design
+func TaggedHash(tag string, msgs ...[]byte) []byte {
+	th := sha256.Sum256([]byte(tag))
+	h := sha256.New()
+	h.Write(th[:])
+	h.Write(th[:])
+	for _, msg := range msgs {
+		h.Write(msg)
+	}
+
+	return h.Sum(nil)
+}
+
+// TaprootTweak returns t = hash_TapTweak(P || merkle root),
+// the merkle root is nil for outputs without a script path
+// see https://github.com/bitcoin/bips/blob/master/bip-0341.mediawiki#constructing-and-spending-taproot-outputs
+func TaprootTweak(internalKey XOnlyPublicKey, merkleRoot []byte) ([]byte, error) {
+	if len(merkleRoot) != 0 && len(merkleRoot) != 32 {
+		return nil, ErrTaprootBadMerkleRoot
+	}
+
+	return TaggedHash("TapTweak", internalKey[:], merkleRoot), nil
+}
+
+// TaprootTweakPublicKey returns the output key Q = P + tG and whether Q has odd y,
+// the parity is the low bit of the control block's first byte
+func TaprootTweakPublicKey(internalKey XOnlyPublicKey, merkleRoot []byte) (XOnlyPublicKey, bool, error) {
+	tweak, err := TaprootTweak(internalKey, merkleRoot)
+	if err != nil {
+		return XOnlyPublicKey{}, false, err
+	}
+
+	output, ok := secp256k1.TweakAddPubkey(internalKey.PublicKey(), tweak)
+	if !ok {
+		return XOnlyPublicKey{}, false, ErrTaprootBadTweak
+	}
+
+	return NewPublicKey(output).XOnly()
+}
+
+// TaprootTweakPrivateKey returns the secret of the output key for key-path spending,
+// the secret is negated first if the internal key has odd y
+func TaprootTweakPrivateKey(pk *PrivateKey, merkleRoot []byte) (*PrivateKey, error) {
+	kp, err := NewKeypair(pk)
+	if err != nil {
+		return nil, err
+	}
+	defer kp.PrivateKey.Destroy()
+
+	tweak, err := TaprootTweak(kp.PublicKey, merkleRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	seckey := kp.PrivateKey.secretBytes()
+	defer secp256k1.Zeroize(seckey)
+
+	tweaked, ok := secp256k1.TweakAddSeckey(seckey, tweak)
+	if !ok {
+		return nil, ErrTaprootBadTweak
+	}
+	defer secp256k1.Zeroize(tweaked)
+
+	return newPrivateKey(pk.Network, tweaked, true)
+}
+
+// taprootOutputKey returns the x-only output key committing to no script path
+// see https://github.com/bitcoin/bips/blob/master/bip-0086.mediawiki
+func taprootOutputKey(pubkey PublicKey) ([]byte, error) {
+	if !pubkey.IsCompressed() {
+		return nil, ErrTaprootBadKey
+	}
+
+	internal, _, err := pubkey.XOnly()
+	if err != nil {
+		return nil, ErrTaprootBadKey
+	}
+
+	output, _, err := TaprootTweakPublicKey(internal, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return output.Bytes(), nil
+}
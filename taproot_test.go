@@ -0,0 +1,124 @@
+package bcrypto
+
+import (
+	"encoding/hex"
+	"testing"
+
+	. "github.com/detailyang/go-bprimitives"
+)
+
+// https://github.com/bitcoin/bips/blob/master/bip-0341/wallet-test-vectors.json
+func TestTaprootTweakPublicKey(t *testing.T) {
+	tests := []struct {
+		internal   string
+		merkleRoot string
+		tweak      string
+		output     string
+		odd        bool
+		address    string
+	}{
+		{
+			"d6889cb081036e0faefa3a35157ad71086b123b2b144b649798b494c300a961d",
+			"",
+			"b86e7be8f39bab32a6f2c0443abbc210f0edac0e2c53d501b36b64437d9c6c70",
+			"53a1f6e454df1aa2776a2814a721372d6258050de330b3c6d10ee8f4e0dda343",
+			true,
+			"bc1p2wsldez5mud2yam29q22wgfh9439spgduvct83k3pm50fcxa5dps59h4z5",
+		},
+		{
+			"187791b6f712a8ea41c8ecdd0ee77fab3e85263b37e1ec18a3651926b3a6cf27",
+			"5b75adecf53548f3ec6ad7d78383bf84cc57b55a3127c72b9a2481752dd88b21",
+			"cbd8679ba636c1110ea247542cfbd964131a6be84f873f7f3b62a777528ed001",
+			"147c9c57132f6e7ecddba9800bb0c4449251c92a1e60371ee77557b6620f3ea3",
+			true,
+			"bc1pz37fc4cn9ah8anwm4xqqhvxygjf9rjf2resrw8h8w4tmvcs0863sa2e586",
+		},
+		{
+			"93478e9488f956df2396be2ce6c5cced75f900dfa18e7dabd2428aae78451820",
+			"c525714a7f49c28aedbbba78c005931a81c234b2f6c99a73e4d06082adc8bf2b",
+			"6af9e28dbf9d6aaf027696e2598a5b3d056f5fd2355a7fd5a37a0e5008132d30",
+			"e4d810fd50586274face62b8a807eb9719cef49c04177cc6b76a9a4251d5450e",
+			false,
+			"bc1punvppl2stp38f7kwv2u2spltjuvuaayuqsthe34hd2dyy5w4g58qqfuag5",
+		},
+	}
+
+	for _, test := range tests {
+		internal, err := NewXOnlyPublicKeyFromHexString(test.internal)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		merkleRoot, _ := hex.DecodeString(test.merkleRoot)
+		tweak, err := TaprootTweak(internal, merkleRoot)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if hex.EncodeToString(tweak) != test.tweak {
+			t.Errorf("expect tweak %s got %x", test.tweak, tweak)
+		}
+
+		output, odd, err := TaprootTweakPublicKey(internal, merkleRoot)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if output.Hex() != test.output || odd != test.odd {
+			t.Errorf("expect %s odd %v got %s odd %v", test.output, test.odd, output, odd)
+		}
+
+		address := NewAddress(AddressP2TR, Mainet, output.Bytes())
+		if address.String() != test.address {
+			t.Errorf("expect %s got %s", test.address, address)
+		}
+	}
+
+	internal, _ := NewXOnlyPublicKeyFromHexString(tests[0].internal)
+	if _, _, err := TaprootTweakPublicKey(internal, make([]byte, 31)); err != ErrTaprootBadMerkleRoot {
+		t.Errorf("expect ErrTaprootBadMerkleRoot got %v", err)
+	}
+}
+
+func TestTaprootTweakPrivateKey(t *testing.T) {
+	data, _ := hex.DecodeString("6b973d88838f27366ed61c9ad6367663045cb456e28335c109e30717ae0c6baa")
+	secret, err := NewReadBuffer(data).GetHash()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pk, err := NewPrivateKeyFromHash(Mainet, secret, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tweaked, err := TaprootTweakPrivateKey(pk, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := "2405b971772ad26915c8dcdf10f238753a9b837e5f8e6a86fd7c0cce5b7296d9"
+	if hex.EncodeToString(tweaked.secretBytes()) != expect {
+		t.Errorf("expect %s got %x", expect, tweaked.secretBytes())
+	}
+
+	// the tweaked secret controls the BIP86 output key
+	pubkey, err := tweaked.PublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	output, _, err := pubkey.XOnly()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	address, err := pk.Address(AddressP2TR)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if output.Hex() != hex.EncodeToString(address.Hash) {
+		t.Errorf("expect %x got %s", address.Hash, output)
+	}
+}
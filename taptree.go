@@ -0,0 +1,357 @@
+package bcrypto
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+)
+
+const (
+	// TapLeafVersionTapscript is the leaf version of BIP342 scripts
+	TapLeafVersionTapscript = 0xc0
+
+	// tapLeafMask clears the output key parity bit of the control block's first byte
+	tapLeafMask = 0xfe
+	// tapMaxDepth is the maximum length of a control block's merkle path
+	tapMaxDepth = 128
+	// tapAnnexTag is the first byte of a witness annex, it can not be a leaf version
+	tapAnnexTag = 0x50
+)
+
+var (
+	// ErrTapTreeEmpty represents a tree is built without leaves
+	ErrTapTreeEmpty = errors.New("taptree: no leaves")
+	// ErrTapTreeBadLeafVersion represents the leaf version is odd or clashes with the annex tag
+	ErrTapTreeBadLeafVersion = errors.New("taptree: bad leaf version")
+	// ErrTapTreeTooDeep represents a leaf is deeper than the 128 levels a control block can prove
+	ErrTapTreeTooDeep = errors.New("taptree: tree too deep")
+	// ErrTapTreeBadIndex represents the leaf index is out of range
+	ErrTapTreeBadIndex = errors.New("taptree: bad leaf index")
+	// ErrControlBlockBadFormat represents the control block has a bad length or leaf version
+	ErrControlBlockBadFormat = errors.New("taptree: bad control block")
+)
+
+// TapLeaf is a script committed to by a taproot output
+type TapLeaf struct {
+	Script  []byte
+	Version byte
+	// Weight is the relative spending probability used by NewHuffmanTapTree
+	Weight int
+}
+
+// NewTapLeaf returns the tapscript leaf of the script with weight 1
+func NewTapLeaf(script []byte) TapLeaf {
+	return TapLeaf{
+		Script:  script,
+		Version: TapLeafVersionTapscript,
+		Weight:  1,
+	}
+}
+
+// Hash returns hash_TapLeaf(version || compact size || script)
+// see https://github.com/bitcoin/bips/blob/master/bip-0341.mediawiki#constructing-and-spending-taproot-outputs
+func (l TapLeaf) Hash() []byte {
+	var buf bytes.Buffer
+	buf.WriteByte(l.Version & tapLeafMask)
+	putVarBytes(&buf, l.Script)
+	return TaggedHash("TapLeaf", buf.Bytes())
+}
+
+func (l TapLeaf) check() error {
+	if l.Version&^tapLeafMask != 0 || l.Version == tapAnnexTag {
+		return ErrTapTreeBadLeafVersion
+	}
+
+	return nil
+}
+
+// TapBranchHash returns hash_TapBranch of the two child hashes in lexicographic order
+func TapBranchHash(a, b []byte) []byte {
+	if bytes.Compare(b, a) < 0 {
+		a, b = b, a
+	}
+
+	return TaggedHash("TapBranch", a, b)
+}
+
+// TapTree is a node of a taproot script tree, either a leaf or a branch of two subtrees
+type TapTree struct {
+	Leaf        *TapLeaf
+	Left, Right *TapTree
+}
+
+// NewTapTreeLeaf returns the tree of a single leaf
+func NewTapTreeLeaf(leaf TapLeaf) *TapTree {
+	return &TapTree{Leaf: &leaf}
+}
+
+// NewTapTreeBranch returns the tree with the two subtrees as children
+func NewTapTreeBranch(left, right *TapTree) *TapTree {
+	return &TapTree{Left: left, Right: right}
+}
+
+// NewTapTree builds a balanced tree by pairing the leaves level by level in order
+func NewTapTree(leaves ...TapLeaf) (*TapTree, error) {
+	if len(leaves) == 0 {
+		return nil, ErrTapTreeEmpty
+	}
+
+	nodes := make([]*TapTree, len(leaves))
+	for i, leaf := range leaves {
+		nodes[i] = NewTapTreeLeaf(leaf)
+	}
+
+	for len(nodes) > 1 {
+		next := make([]*TapTree, 0, (len(nodes)+1)/2)
+		for i := 0; i+1 < len(nodes); i += 2 {
+			next = append(next, NewTapTreeBranch(nodes[i], nodes[i+1]))
+		}
+		if len(nodes)%2 == 1 {
+			next = append(next, nodes[len(nodes)-1])
+		}
+		nodes = next
+	}
+
+	return nodes[0], nodes[0].check()
+}
+
+// NewHuffmanTapTree builds the tree minimizing the expected control block size,
+// likely leaves with higher Weight end up closer to the root
+func NewHuffmanTapTree(leaves ...TapLeaf) (*TapTree, error) {
+	if len(leaves) == 0 {
+		return nil, ErrTapTreeEmpty
+	}
+
+	type weighted struct {
+		tree   *TapTree
+		weight int
+	}
+
+	queue := make([]weighted, len(leaves))
+	for i, leaf := range leaves {
+		queue[i] = weighted{NewTapTreeLeaf(leaf), leaf.Weight}
+	}
+
+	for len(queue) > 1 {
+		// the stable sort keeps the insertion order of equal weights
+		sort.SliceStable(queue, func(i, j int) bool {
+			return queue[i].weight < queue[j].weight
+		})
+
+		merged := weighted{
+			tree:   NewTapTreeBranch(queue[0].tree, queue[1].tree),
+			weight: queue[0].weight + queue[1].weight,
+		}
+		queue = append(queue[2:], merged)
+	}
+
+	return queue[0].tree, queue[0].tree.check()
+}
+
+func (t *TapTree) check() error {
+	return t.checkDepth(0)
+}
+
+func (t *TapTree) checkDepth(depth int) error {
+	if t == nil {
+		return ErrTapTreeEmpty
+	}
+
+	if depth > tapMaxDepth {
+		return ErrTapTreeTooDeep
+	}
+
+	if t.Leaf != nil {
+		return t.Leaf.check()
+	}
+
+	if err := t.Left.checkDepth(depth + 1); err != nil {
+		return err
+	}
+
+	return t.Right.checkDepth(depth + 1)
+}
+
+// Hash returns the merkle root of the tree, ErrTapTreeEmpty if a branch misses a child
+func (t *TapTree) Hash() ([]byte, error) {
+	if err := t.check(); err != nil {
+		return nil, err
+	}
+
+	return t.hash(), nil
+}
+
+func (t *TapTree) hash() []byte {
+	if t.Leaf != nil {
+		return t.Leaf.Hash()
+	}
+
+	return TapBranchHash(t.Left.hash(), t.Right.hash())
+}
+
+// Leaves returns the leaves in depth-first order, which is the order of leaf indexes
+func (t *TapTree) Leaves() []TapLeaf {
+	if t == nil {
+		return nil
+	}
+
+	if t.Leaf != nil {
+		return []TapLeaf{*t.Leaf}
+	}
+
+	return append(t.Left.Leaves(), t.Right.Leaves()...)
+}
+
+// tapLeafPath is a leaf with its merkle path from the leaf up to the root
+type tapLeafPath struct {
+	leaf *TapLeaf
+	path [][]byte
+}
+
+// walk returns the hash of the tree and its leaves depth-first with their merkle paths,
+// every node is hashed once and its hash appended to the paths of the sibling's leaves
+func (t *TapTree) walk() ([]byte, []tapLeafPath) {
+	if t.Leaf != nil {
+		return t.Leaf.Hash(), []tapLeafPath{{leaf: t.Leaf}}
+	}
+
+	left, leftLeaves := t.Left.walk()
+	right, rightLeaves := t.Right.walk()
+
+	for i := range leftLeaves {
+		leftLeaves[i].path = append(leftLeaves[i].path, right)
+	}
+
+	for i := range rightLeaves {
+		rightLeaves[i].path = append(rightLeaves[i].path, left)
+	}
+
+	return TapBranchHash(left, right), append(leftLeaves, rightLeaves...)
+}
+
+// OutputKey returns the output key committing to the tree and whether it has odd y
+func (t *TapTree) OutputKey(internalKey XOnlyPublicKey) (XOnlyPublicKey, bool, error) {
+	root, err := t.Hash()
+	if err != nil {
+		return XOnlyPublicKey{}, false, err
+	}
+
+	return TaprootTweakPublicKey(internalKey, root)
+}
+
+// ControlBlock returns the control block proving the leaf at index is committed to
+// by the output key of the internal key
+func (t *TapTree) ControlBlock(internalKey XOnlyPublicKey, index int) (*ControlBlock, error) {
+	if err := t.check(); err != nil {
+		return nil, err
+	}
+
+	root, leaves := t.walk()
+	if index < 0 || index >= len(leaves) {
+		return nil, ErrTapTreeBadIndex
+	}
+
+	_, odd, err := TaprootTweakPublicKey(internalKey, root)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ControlBlock{
+		LeafVersion:  leaves[index].leaf.Version,
+		OutputKeyOdd: odd,
+		InternalKey:  internalKey,
+		Path:         leaves[index].path,
+	}, nil
+}
+
+// NewTaprootAddress returns the P2TR address of the internal key committing to the tree,
+// a nil tree commits to no script path
+func NewTaprootAddress(network Network, internalKey XOnlyPublicKey, tree *TapTree) (*Address, error) {
+	var merkleRoot []byte
+	if tree != nil {
+		root, err := tree.Hash()
+		if err != nil {
+			return nil, err
+		}
+		merkleRoot = root
+	}
+
+	output, _, err := TaprootTweakPublicKey(internalKey, merkleRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewAddress(AddressP2TR, network, output.Bytes()), nil
+}
+
+// ControlBlock is the last witness item of a script path spend
+type ControlBlock struct {
+	LeafVersion  byte
+	OutputKeyOdd bool
+	InternalKey  XOnlyPublicKey
+	// Path holds the merkle branch hashes from the leaf up to the root
+	Path [][]byte
+}
+
+// ParseControlBlock decodes the 33 + 32m byte control block
+func ParseControlBlock(data []byte) (*ControlBlock, error) {
+	if len(data) < 33 || (len(data)-33)%32 != 0 || (len(data)-33)/32 > tapMaxDepth {
+		return nil, ErrControlBlockBadFormat
+	}
+
+	internalKey, err := ParseXOnlyPublicKey(data[1:33])
+	if err != nil {
+		return nil, err
+	}
+
+	cb := &ControlBlock{
+		LeafVersion:  data[0] & tapLeafMask,
+		OutputKeyOdd: data[0]&^tapLeafMask == 1,
+		InternalKey:  internalKey,
+	}
+
+	if cb.LeafVersion == tapAnnexTag {
+		return nil, ErrControlBlockBadFormat
+	}
+
+	for i := 33; i < len(data); i += 32 {
+		cb.Path = append(cb.Path, append([]byte{}, data[i:i+32]...))
+	}
+
+	return cb, nil
+}
+
+// Bytes serializes the control block
+func (c *ControlBlock) Bytes() []byte {
+	first := c.LeafVersion & tapLeafMask
+	if c.OutputKeyOdd {
+		first |= 1
+	}
+
+	data := append([]byte{first}, c.InternalKey[:]...)
+	for _, hash := range c.Path {
+		data = append(data, hash...)
+	}
+
+	return data
+}
+
+// RootHash returns the merkle root implied by the path and the leaf script
+func (c *ControlBlock) RootHash(script []byte) []byte {
+	hash := TapLeaf{Script: script, Version: c.LeafVersion}.Hash()
+	for _, node := range c.Path {
+		hash = TapBranchHash(hash, node)
+	}
+
+	return hash
+}
+
+// Verify checks the script is committed to by the output key, as BIP341 script path validation does
+func (c *ControlBlock) Verify(outputKey XOnlyPublicKey, script []byte) bool {
+	expect, odd, err := TaprootTweakPublicKey(c.InternalKey, c.RootHash(script))
+	if err != nil {
+		return false
+	}
+
+	return expect == outputKey && odd == c.OutputKeyOdd
+}
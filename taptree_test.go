@@ -0,0 +1,187 @@
+package bcrypto
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+// https://github.com/bitcoin/bips/blob/master/bip-0341/wallet-test-vectors.json
+func TestTapTreeVectors(t *testing.T) {
+	tests := []struct {
+		internal      string
+		tree          *TapTree
+		merkleRoot    string
+		address       string
+		controlBlocks []string
+	}{
+		{
+			"187791b6f712a8ea41c8ecdd0ee77fab3e85263b37e1ec18a3651926b3a6cf27",
+			NewTapTreeLeaf(tapLeafFromHex("20d85a959b0290bf19bb89ed43c916be835475d013da4b362117393e25a48229b8ac", 0xc0)),
+			"5b75adecf53548f3ec6ad7d78383bf84cc57b55a3127c72b9a2481752dd88b21",
+			"bc1pz37fc4cn9ah8anwm4xqqhvxygjf9rjf2resrw8h8w4tmvcs0863sa2e586",
+			[]string{"c1187791b6f712a8ea41c8ecdd0ee77fab3e85263b37e1ec18a3651926b3a6cf27"},
+		},
+		{
+			"93478e9488f956df2396be2ce6c5cced75f900dfa18e7dabd2428aae78451820",
+			NewTapTreeLeaf(tapLeafFromHex("20b617298552a72ade070667e86ca63b8f5789a9fe8731ef91202a91c9f3459007ac", 0xc0)),
+			"c525714a7f49c28aedbbba78c005931a81c234b2f6c99a73e4d06082adc8bf2b",
+			"bc1punvppl2stp38f7kwv2u2spltjuvuaayuqsthe34hd2dyy5w4g58qqfuag5",
+			[]string{"c093478e9488f956df2396be2ce6c5cced75f900dfa18e7dabd2428aae78451820"},
+		},
+		{
+			"f9f400803e683727b14f463836e1e78e1c64417638aa066919291a225f0e8dd8",
+			NewTapTreeBranch(
+				NewTapTreeLeaf(tapLeafFromHex("2044b178d64c32c4a05cc4f4d1407268f764c940d20ce97abfd44db5c3592b72fdac", 0xc0)),
+				NewTapTreeLeaf(tapLeafFromHex("07546170726f6f74", 0xc0)),
+			),
+			"ab179431c28d3b68fb798957faf5497d69c883c6fb1e1cd9f81483d87bac90cc",
+			"bc1pwl3s54fzmk0cjnpl3w9af39je7pv5ldg504x5guk2hpecpg2kgsqaqstjq",
+			[]string{
+				"c1f9f400803e683727b14f463836e1e78e1c64417638aa066919291a225f0e8dd82cb2b90daa543b544161530c925f285b06196940d6085ca9474d41dc3822c5cb",
+				"c1f9f400803e683727b14f463836e1e78e1c64417638aa066919291a225f0e8dd864512fecdb5afa04f98839b50e6f0cb7b1e539bf6f205f67934083cdcc3c8d89",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		internal, err := NewXOnlyPublicKeyFromHexString(test.internal)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		root, err := test.tree.Hash()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if hex.EncodeToString(root) != test.merkleRoot {
+			t.Errorf("expect merkle root %s got %x", test.merkleRoot, root)
+		}
+
+		address, err := NewTaprootAddress(Mainet, internal, test.tree)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if address.String() != test.address {
+			t.Errorf("expect %s got %s", test.address, address)
+		}
+
+		output, err := ParseXOnlyPublicKey(address.Hash)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		leaves := test.tree.Leaves()
+		for i, expect := range test.controlBlocks {
+			cb, err := test.tree.ControlBlock(internal, i)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if hex.EncodeToString(cb.Bytes()) != expect {
+				t.Errorf("expect control block %s got %x", expect, cb.Bytes())
+			}
+
+			data, _ := hex.DecodeString(expect)
+			parsed, err := ParseControlBlock(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !parsed.Verify(output, leaves[i].Script) {
+				t.Errorf("expect control block %d verified", i)
+			}
+		}
+
+		if _, err := test.tree.ControlBlock(internal, len(leaves)); err != ErrTapTreeBadIndex {
+			t.Errorf("expect ErrTapTreeBadIndex got %v", err)
+		}
+	}
+}
+
+func TestTapTreeLayout(t *testing.T) {
+	a, b, c, d := NewTapLeaf([]byte{0x51}), NewTapLeaf([]byte{0x52}), NewTapLeaf([]byte{0x53}), NewTapLeaf([]byte{0x54})
+
+	balanced, err := NewTapTree(a, b, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// ((a, b), c)
+	expect := "609f09890e4348cc5bcc26e51c432c7830162715d64aab7c306f853c9da06a7b"
+	if root, err := balanced.Hash(); err != nil || hex.EncodeToString(root) != expect {
+		t.Errorf("expect balanced root %s got %x %v", expect, root, err)
+	}
+
+	a.Weight, d.Weight = 5, 2
+	huffman, err := NewHuffmanTapTree(a, b, c, d)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// ((d, (b, c)), a)
+	expect = "7dae56df1d4cfa6850fe97cff94903726048998a92adf60b5ec311c4aceb12e8"
+	if root, err := huffman.Hash(); err != nil || hex.EncodeToString(root) != expect {
+		t.Errorf("expect huffman root %s got %x %v", expect, root, err)
+	}
+
+	internal, _ := NewXOnlyPublicKeyFromHexString("f9f400803e683727b14f463836e1e78e1c64417638aa066919291a225f0e8dd8")
+	output, _, err := huffman.OutputKey(internal)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, leaf := range huffman.Leaves() {
+		cb, err := huffman.ControlBlock(internal, i)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !cb.Verify(output, leaf.Script) {
+			t.Errorf("expect leaf %x verified", leaf.Script)
+		}
+
+		// the heaviest leaf sits next to the root
+		if leaf.Weight == 5 && len(cb.Path) != 1 {
+			t.Errorf("expect heaviest leaf at depth 1 got %d", len(cb.Path))
+		}
+	}
+
+	if _, err := NewTapTree(); err != ErrTapTreeEmpty {
+		t.Errorf("expect ErrTapTreeEmpty got %v", err)
+	}
+
+	for _, version := range []byte{0xc1, 0x50} {
+		if _, err := NewTapTree(TapLeaf{Script: []byte{0x51}, Version: version}); err != ErrTapTreeBadLeafVersion {
+			t.Errorf("%x: expect ErrTapTreeBadLeafVersion got %v", version, err)
+		}
+	}
+
+	// a branch missing a child is rejected rather than dereferenced
+	incomplete := NewTapTreeBranch(NewTapTreeLeaf(a), NewTapTreeBranch(NewTapTreeLeaf(b), nil))
+	if _, err := incomplete.Hash(); err != ErrTapTreeEmpty {
+		t.Errorf("expect ErrTapTreeEmpty got %v", err)
+	}
+
+	if _, err := incomplete.ControlBlock(internal, 0); err != ErrTapTreeEmpty {
+		t.Errorf("expect ErrTapTreeEmpty got %v", err)
+	}
+
+	if _, err := NewTaprootAddress(Mainet, internal, incomplete); err != ErrTapTreeEmpty {
+		t.Errorf("expect ErrTapTreeEmpty got %v", err)
+	}
+
+	if leaves := incomplete.Leaves(); len(leaves) != 2 {
+		t.Errorf("expect 2 leaves got %d", len(leaves))
+	}
+
+	if _, err := ParseControlBlock(make([]byte, 34)); err != ErrControlBlockBadFormat {
+		t.Errorf("expect ErrControlBlockBadFormat got %v", err)
+	}
+}
+
+func tapLeafFromHex(script string, version byte) TapLeaf {
+	data, _ := hex.DecodeString(script)
+	return TapLeaf{Script: data, Version: version, Weight: 1}
+}
@@ -0,0 +1,19 @@
+// Package wordlists holds the official non-English BIP39 wordlists,
+// the English wordlist is built into bcrypto
+// see https://github.com/bitcoin/bips/blob/master/bip-0039/bip-0039-wordlists.md
+package wordlists
+
+import (
+	"strings"
+
+	"github.com/detailyang/go-bcrypto"
+)
+
+func mustWordlist(language, separator, words string) *bcrypto.Wordlist {
+	w, err := bcrypto.NewWordlist(language, separator, strings.Fields(words))
+	if err != nil {
+		panic(language + ": " + err.Error())
+	}
+
+	return w
+}
@@ -0,0 +1,117 @@
+package wordlists
+
+import (
+	"encoding/hex"
+	"hash/crc32"
+	"strings"
+	"testing"
+
+	"github.com/detailyang/go-bcrypto"
+	"golang.org/x/text/unicode/norm"
+)
+
+func TestWordlistChecksum(t *testing.T) {
+	// crc32 of the official wordlist files
+	tests := []struct {
+		wordlist *bcrypto.Wordlist
+		expect   uint32
+	}{
+		{bcrypto.EnglishWordlist, 0xc1dbd296},
+		{ChineseSimplified, 0xe3721bbf},
+		{ChineseTraditional, 0x3c20b443},
+		{Czech, 0xd1b5fda0},
+		{French, 0x3e56b216},
+		{Italian, 0x2fc7d07e},
+		{Japanese, 0x0acc1419},
+		{Korean, 0x4ef461eb},
+		{Spanish, 0x266e4f3d},
+	}
+
+	for _, test := range tests {
+		var sb strings.Builder
+		for i := 0; i < bcrypto.WordlistSize; i++ {
+			sb.WriteString(test.wordlist.Word(i))
+			sb.WriteByte('\n')
+		}
+
+		if sum := crc32.ChecksumIEEE([]byte(sb.String())); sum != test.expect {
+			t.Errorf("%s: expect %08x got %08x", test.wordlist.Language, test.expect, sum)
+		}
+	}
+}
+
+func TestMnemonicLanguages(t *testing.T) {
+	tests := []struct {
+		wordlist   *bcrypto.Wordlist
+		entropy    string
+		mnemonic   string
+		passphrase string
+		seed       string
+	}{
+		// https://github.com/bip32JP/bip32JP.github.io/blob/master/test_JP_BIP39.json
+		{
+			Japanese,
+			"00000000000000000000000000000000",
+			"あいこくしん　あいこくしん　あいこくしん　あいこくしん　あいこくしん　あいこくしん　あいこくしん　あいこくしん　あいこくしん　あいこくしん　あいこくしん　あおぞら",
+			"㍍ガバヴァぱばぐゞちぢ十人十色",
+			"a262d6fb6122ecf45be09c50492b31f92e9beb7d9a845987a02cefda57a15f9c467a17872029a9e92299b5cbdf306e3a0ee620245cbd508959b6cb7ca637bd55",
+		},
+		{
+			Japanese,
+			"7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f",
+			"そつう　れきだい　ほんやく　わかす　りくつ　ばいか　ろせん　やちん　そつう　れきだい　ほんやく　わかめ",
+			"㍍ガバヴァぱばぐゞちぢ十人十色",
+			"aee025cbe6ca256862f889e48110a6a382365142f7d16f2b9545285b3af64e542143a577e9c144e101a6bdca18f8d97ec3366ebf5b088b1c1af9bc31346e60d9",
+		},
+		{
+			Spanish,
+			"9e885d952ad362caeb4efe34a8e91bd2",
+			"obra diadema gorila farmacia colgar gorra pausa talar cocina duda dragón optar",
+			"",
+			"24be92fac4cf53a5e5331018435a91688550cfd76ba3c892efeb0fdbdac87acefd1290d0b548b93acf55013b8bd319f3e7df68569a787ed5e41d34d808f8f07a",
+		},
+		{
+			French,
+			"9e885d952ad362caeb4efe34a8e91bd2",
+			"monument dépenser féroce entasser comédie ferveur optique sonnette codifier discuter dioxyde nerveux",
+			"",
+			"fdc2c01911906bae352c37668d5ff5ca7734ac8ddca35704488e3cdc0da8eb8bc5d1bcace093cc41b575d5591ffdbe1c261c2d0b511893256ea242dae4cd508f",
+		},
+	}
+
+	for _, test := range tests {
+		entropy, _ := hex.DecodeString(test.entropy)
+
+		mnemonic, err := bcrypto.NewMnemonic(entropy, test.wordlist)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// the wordlists are NFKD normalized while the vectors may be composed
+		if norm.NFKD.String(mnemonic) != norm.NFKD.String(test.mnemonic) {
+			t.Errorf("expect %s got %s", test.mnemonic, mnemonic)
+		}
+
+		decoded, err := bcrypto.MnemonicToEntropy(test.mnemonic, test.wordlist)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if hex.EncodeToString(decoded) != test.entropy {
+			t.Errorf("expect %s got %x", test.entropy, decoded)
+		}
+
+		seed, err := bcrypto.NewSeedFromMnemonic(test.mnemonic, test.passphrase, test.wordlist)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if hex.EncodeToString(seed) != test.seed {
+			t.Errorf("expect %s got %x", test.seed, seed)
+		}
+	}
+
+	if err := bcrypto.ValidateMnemonic("obra diadema gorila farmacia colgar gorra pausa talar cocina duda dragón optar", bcrypto.EnglishWordlist); err == nil {
+		t.Error("expect a Spanish mnemonic to fail against the English wordlist")
+	}
+}
@@ -0,0 +1,140 @@
+package bcrypto
+
+import (
+	"encoding/hex"
+	"errors"
+
+	"github.com/detailyang/go-bcrypto/secp256k1"
+)
+
+// XOnlyPublicKeySize is the length of a BIP340 public key
+const XOnlyPublicKeySize = 32
+
+var (
+	// ErrXOnlyBadFormat represents the bytes are not the x coordinate of a point on secp256k1
+	ErrXOnlyBadFormat = errors.New("bad x-only public key format")
+)
+
+// XOnlyPublicKey is the x coordinate of the point with even y, see BIP340
+type XOnlyPublicKey [XOnlyPublicKeySize]byte
+
+// ParseXOnlyPublicKey parses the 32-byte x coordinate, which must be on the curve
+func ParseXOnlyPublicKey(data []byte) (XOnlyPublicKey, error) {
+	var x XOnlyPublicKey
+	if len(data) != XOnlyPublicKeySize {
+		return x, ErrXOnlyBadFormat
+	}
+
+	if _, ok := secp256k1.ReencodePubkey(append([]byte{0x02}, data...), true); !ok {
+		return x, ErrXOnlyBadFormat
+	}
+
+	copy(x[:], data)
+	return x, nil
+}
+
+// NewXOnlyPublicKeyFromHexString parses the hex encoded x-only public key
+func NewXOnlyPublicKeyFromHexString(hexstring string) (XOnlyPublicKey, error) {
+	data, err := hex.DecodeString(hexstring)
+	if err != nil {
+		return XOnlyPublicKey{}, err
+	}
+
+	return ParseXOnlyPublicKey(data)
+}
+
+// XOnly returns the x-only form of the public key and whether its y is odd,
+// an odd key is the negation of the even point the x-only key stands for
+func (p PublicKey) XOnly() (XOnlyPublicKey, bool, error) {
+	var x XOnlyPublicKey
+	if len(p) == 0 {
+		return x, false, ErrPublicBadFormat
+	}
+
+	compressed, ok := secp256k1.ReencodePubkey(p, true)
+	if !ok {
+		return x, false, ErrPublicBadFormat
+	}
+
+	copy(x[:], compressed[1:])
+	return x, compressed[0] == 0x03, nil
+}
+
+// PublicKey returns the compressed public key of the point with even y
+func (x XOnlyPublicKey) PublicKey() PublicKey {
+	return NewPublicKey(append([]byte{0x02}, x[:]...))
+}
+
+// Verify checks the 64-byte BIP340 signature of the message
+func (x XOnlyPublicKey) Verify(msg, sig []byte) bool {
+	return secp256k1.SchnorrVerify(x[:], msg, sig)
+}
+
+func (x XOnlyPublicKey) Bytes() []byte {
+	return append([]byte{}, x[:]...)
+}
+
+func (x XOnlyPublicKey) Hex() string {
+	return hex.EncodeToString(x[:])
+}
+
+func (x XOnlyPublicKey) String() string {
+	return x.Hex()
+}
+
+// Keypair is a private key normalized so its public key has even y,
+// as BIP340 signing and BIP341 tweaking expect
+type Keypair struct {
+	// PrivateKey holds the secret d or n - d, whichever has an even public key
+	PrivateKey *PrivateKey
+	// PublicKey is the x-only public key of the secret
+	PublicKey XOnlyPublicKey
+	// Negated reports whether the secret of the original private key was negated
+	Negated bool
+}
+
+// NewKeypair derives the keypair of the private key, negating the secret if its public key has odd y
+func NewKeypair(pk *PrivateKey) (*Keypair, error) {
+	pubkey, err := pk.PublicKey()
+	if err != nil {
+		return nil, err
+	}
+
+	x, odd, err := pubkey.XOnly()
+	if err != nil {
+		return nil, err
+	}
+
+	secret := pk.secretBytes()
+	defer secp256k1.Zeroize(secret)
+
+	if odd {
+		negated, err := secp256k1.NegateSeckey(secret)
+		if err != nil {
+			return nil, ErrPrivateBadSecret
+		}
+		defer secp256k1.Zeroize(negated)
+		secret = negated
+	}
+
+	normalized, err := newPrivateKey(pk.Network, secret, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Keypair{
+		PrivateKey: normalized,
+		PublicKey:  x,
+		Negated:    odd,
+	}, nil
+}
+
+// Sign returns the 64-byte BIP340 signature of the 32-byte hash
+func (kp *Keypair) Sign(hash, auxRand []byte) ([]byte, error) {
+	return kp.PrivateKey.SignSchnorr(hash, auxRand)
+}
+
+// Verify checks the 64-byte BIP340 signature of the message
+func (kp *Keypair) Verify(msg, sig []byte) bool {
+	return kp.PublicKey.Verify(msg, sig)
+}
@@ -0,0 +1,122 @@
+package bcrypto
+
+import (
+	"encoding/hex"
+	"testing"
+
+	. "github.com/detailyang/go-bprimitives"
+)
+
+func TestXOnlyPublicKey(t *testing.T) {
+	tests := []struct {
+		pubkey string
+		xonly  string
+		odd    bool
+	}{
+		{"0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798", "79be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798", false},
+		{"0479be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798483ada7726a3c4655da4fbfc0e1108a8fd17b448a68554199c47d08ffb10d4b8", "79be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798", false},
+		{"0325d1dff95105f5253c4022f628a996ad3a0d95fbf21d468a1b33f8c160d8f517", "25d1dff95105f5253c4022f628a996ad3a0d95fbf21d468a1b33f8c160d8f517", true},
+	}
+
+	for _, test := range tests {
+		data, _ := hex.DecodeString(test.pubkey)
+		x, odd, err := NewPublicKey(data).XOnly()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if x.Hex() != test.xonly || odd != test.odd {
+			t.Errorf("expect %s odd %v got %s odd %v", test.xonly, test.odd, x, odd)
+		}
+
+		parsed, err := NewXOnlyPublicKeyFromHexString(test.xonly)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if parsed != x {
+			t.Errorf("expect %s got %s", x, parsed)
+		}
+
+		if expect := "02" + test.xonly; parsed.PublicKey().Hex() != expect {
+			t.Errorf("expect %s got %s", expect, parsed.PublicKey())
+		}
+	}
+
+	invalid := []string{
+		"",
+		"79be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f817",
+		// not on the curve
+		"eefdea4cdb677750a420fee807eacf21eb9898ae79b9768766e4faa04a2d4a34",
+		// exceeds the field size
+		"fffffffffffffffffffffffffffffffffffffffffffffffffffffffefffffc30",
+	}
+
+	for _, test := range invalid {
+		if _, err := NewXOnlyPublicKeyFromHexString(test); err != ErrXOnlyBadFormat {
+			t.Errorf("%s: expect ErrXOnlyBadFormat got %v", test, err)
+		}
+	}
+}
+
+func TestKeypair(t *testing.T) {
+	tests := []struct {
+		secret  string
+		xonly   string
+		negated bool
+	}{
+		{"b7e151628aed2a6abf7158809cf4f3c762e7160f38b4da56a784d9045190cfef", "dff1d77f2a671c5f36183726db2341be58feae1da2deced843240f7b502ba659", false},
+		{"0b432b2677937381aef05bb02a66ecd012773062cf3fa2549e44f58ed2401710", "25d1dff95105f5253c4022f628a996ad3a0d95fbf21d468a1b33f8c160d8f517", true},
+	}
+
+	for _, test := range tests {
+		data, _ := hex.DecodeString(test.secret)
+		secret, err := NewReadBuffer(data).GetHash()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		pk, err := NewPrivateKeyFromHash(Mainet, secret, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		kp, err := NewKeypair(pk)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if kp.PublicKey.Hex() != test.xonly || kp.Negated != test.negated {
+			t.Errorf("expect %s negated %v got %s negated %v", test.xonly, test.negated, kp.PublicKey, kp.Negated)
+		}
+
+		pubkey, err := kp.PrivateKey.PublicKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if expect := "02" + test.xonly; pubkey.Hex() != expect {
+			t.Errorf("expect even public key %s got %s", expect, pubkey)
+		}
+
+		// BIP340 signs with the normalized secret either way
+		msg := make([]byte, 32)
+		sig, err := kp.Sign(msg, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expect, err := pk.SignSchnorr(msg, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if hex.EncodeToString(sig) != hex.EncodeToString(expect) {
+			t.Errorf("expect %x got %x", expect, sig)
+		}
+
+		if !kp.Verify(msg, sig) {
+			t.Error("expect signature valid")
+		}
+	}
+}